@@ -0,0 +1,55 @@
+package main
+
+import (
+	"go/ast"
+	"strings"
+)
+
+// Annotation represents a directive declared within the doc comment of an
+// interface method, such as:
+//
+//	// @version v1 (str string) (upper string, err error)
+type Annotation struct {
+	name string // the name of the annotation, without the leading @.
+	args string // everything following the name.
+}
+
+// parseAnnotations returns every Annotation declared within the given doc
+// comment, in the order they were declared.
+func parseAnnotations(doc *ast.CommentGroup) []Annotation {
+	if doc == nil {
+		return nil
+	}
+
+	var annotations []Annotation
+	for _, line := range strings.Split(doc.Text(), "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "@") {
+			continue
+		}
+
+		pieces := strings.SplitN(line[1:], " ", 2)
+		a := Annotation{
+			name: pieces[0],
+		}
+		if len(pieces) > 1 {
+			a.args = strings.TrimSpace(pieces[1])
+		}
+
+		annotations = append(annotations, a)
+	}
+
+	return annotations
+}
+
+// findAnnotations returns the Annotations with the given name.
+func findAnnotations(annotations []Annotation, name string) []Annotation {
+	var result []Annotation
+	for _, a := range annotations {
+		if a.name == name {
+			result = append(result, a)
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,164 @@
+package cache_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ayiga/go-kit-middlewarer/cache"
+)
+
+func TestLRUEvictsLeastRecentlyUsed(t *testing.T) {
+	l := cache.New(2, time.Minute)
+	l.Add("a", 1)
+	l.Add("b", 2)
+
+	// a is now more recently used than b
+	if e, ok := l.Get("a"); !ok || e.Value != 1 {
+		t.Fatalf("Get(a):\ngot:\n\t%v %t\nwant:\n\t%v %t", e.Value, ok, 1, true)
+	}
+
+	l.Add("c", 3)
+	if _, ok := l.Get("b"); ok {
+		t.Errorf("Get(b): expected b to have been evicted")
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, ok := l.Get(key); !ok {
+			t.Errorf("Get(%s): expected %s to be present", key, key)
+		}
+	}
+
+	if got, want := l.Len(), 2; got != want {
+		t.Errorf("Len:\ngot:\n\t%d\nwant:\n\t%d", got, want)
+	}
+}
+
+func TestLRUExpires(t *testing.T) {
+	l := cache.New(0, 10*time.Millisecond)
+	e := l.Add("a", 1)
+	if time.Until(e.Expires) > 10*time.Millisecond {
+		t.Errorf("Expires: expected the entry to expire within its TTL, got %s", time.Until(e.Expires))
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	if _, ok := l.Get("a"); ok {
+		t.Errorf("Get(a): expected a to have expired")
+	}
+	if got, want := l.Len(), 0; got != want {
+		t.Errorf("Len:\ngot:\n\t%d\nwant:\n\t%d", got, want)
+	}
+}
+
+func TestLRURemoveAndPurge(t *testing.T) {
+	l := cache.New(0, time.Minute)
+	l.Add("a", 1)
+	l.Add("b", 2)
+
+	l.Remove("a")
+	if _, ok := l.Get("a"); ok {
+		t.Errorf("Get(a): expected a to have been removed")
+	}
+
+	l.Purge()
+	if got, want := l.Len(), 0; got != want {
+		t.Errorf("Len:\ngot:\n\t%d\nwant:\n\t%d", got, want)
+	}
+}
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		ifNoneMatch string
+		want        bool
+	}{
+		{``, false},
+		{`"abc"`, true},
+		{`W/"abc"`, true},
+		{`"xyz", "abc"`, true},
+		{`"xyz"`, false},
+		{`*`, true},
+	}
+
+	for _, test := range tests {
+		if got := cache.Match(test.ifNoneMatch, `"abc"`); got != test.want {
+			t.Errorf("Match(%q):\ngot:\n\t%t\nwant:\n\t%t", test.ifNoneMatch, got, test.want)
+		}
+	}
+}
+
+func TestHandler(t *testing.T) {
+	handler := cache.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/cached" {
+			cache.SetExpires(r.Context(), time.Now().Add(time.Minute))
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"upper":"ABC"}`)
+	}))
+
+	serve := func(path, ifNoneMatch string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, path, nil)
+		if ifNoneMatch != "" {
+			r.Header.Set("If-None-Match", ifNoneMatch)
+		}
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w
+	}
+
+	w := serve("/cached", "")
+	etag := w.Header().Get("ETag")
+	if w.Code != http.StatusOK || etag == "" || w.Body.String() != `{"upper":"ABC"}` {
+		t.Fatalf("Unexpected response: %d %q %q", w.Code, etag, w.Body.String())
+	}
+	if got, want := w.Header().Get("Cache-Control"), "max-age=59"; got != want {
+		t.Errorf("Cache-Control:\ngot:\n\t%s\nwant:\n\t%s", got, want)
+	}
+
+	w = serve("/cached", etag)
+	if w.Code != http.StatusNotModified || w.Body.Len() != 0 {
+		t.Errorf("Expected an empty 304 Not Modified, got: %d %q", w.Code, w.Body.String())
+	}
+
+	w = serve("/uncached", etag)
+	if w.Code != http.StatusOK || w.Header().Get("ETag") != "" {
+		t.Errorf("Expected an uncached response, got: %d %q", w.Code, w.Header().Get("ETag"))
+	}
+}
+
+func TestHandlerCompressed(t *testing.T) {
+	body := `{"upper":"ABC"}`
+	handler := cache.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cache.SetExpires(r.Context(), time.Now().Add(time.Minute))
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Encoding", "gzip")
+		gw := gzip.NewWriter(w)
+		io.WriteString(gw, body)
+		gw.Close()
+	}))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/cached", nil))
+
+	// the tag is of the uncompressed body, along with its content-coding
+	want := strings.TrimSuffix(cache.ETag([]byte(body)), `"`) + `-gzip"`
+	if got := w.Header().Get("ETag"); got != want {
+		t.Errorf("ETag:\ngot:\n\t%s\nwant:\n\t%s", got, want)
+	}
+
+	gr, err := gzip.NewReader(bytes.NewReader(w.Body.Bytes()))
+	if err != nil {
+		t.Fatalf("Unable to decompress the body: %s", err)
+	}
+
+	if b, _ := ioutil.ReadAll(gr); string(b) != body {
+		t.Errorf("Body:\ngot:\n\t%s\nwant:\n\t%s", b, body)
+	}
+}
@@ -0,0 +1,149 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ayiga/go-kit-middlewarer/encoding"
+)
+
+type contextKey int
+
+const (
+	contextKeyExpires contextKey = iota
+)
+
+// expires holds when the cached result a response is made from expires.
+type expires struct {
+	mu sync.Mutex
+	t  time.Time
+}
+
+// SetExpires records, for a request being served by a Handler, that its
+// response is made from a cached result that expires at the given time.  It
+// has no effect on requests not served by a Handler.
+func SetExpires(ctx context.Context, t time.Time) {
+	if e, ok := ctx.Value(contextKeyExpires).(*expires); ok {
+		e.mu.Lock()
+		e.t = t
+		e.mu.Unlock()
+	}
+}
+
+func (e *expires) get() time.Time {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.t
+}
+
+// ETag returns a strong entity tag for the given response body.
+func ETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + base64.RawURLEncoding.EncodeToString(sum[:18]) + `"`
+}
+
+// representationETag returns a strong entity tag for the given response body,
+// compressed with the given Content-Encoding.  The body is hashed as it was
+// before it was compressed, as compressors aren't bound to produce the same
+// output, and the content-coding is appended, as each is a different
+// representation of the body, needing a tag of its own.
+func representationETag(contentEncoding string, body []byte) string {
+	contentEncoding = strings.ReplaceAll(contentEncoding, " ", "")
+	if contentEncoding == "" || contentEncoding == "identity" {
+		return ETag(body)
+	}
+
+	if rc, err := encoding.DecompressBody(contentEncoding, ioutil.NopCloser(bytes.NewReader(body))); err == nil {
+		if b, err := ioutil.ReadAll(rc); err == nil {
+			body = b
+		}
+		rc.Close()
+	}
+
+	return strings.TrimSuffix(ETag(body), `"`) + "-" + contentEncoding + `"`
+}
+
+// Match reports whether the given If-None-Match header matches the entity
+// tag, using the weak comparison it calls for.
+func Match(ifNoneMatch, etag string) bool {
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, tag := range strings.Split(ifNoneMatch, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == etag {
+			return true
+		}
+	}
+
+	return false
+}
+
+// recorder holds the status and body of a response, so that they may be
+// replaced with a 304 Not Modified.
+type recorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (r *recorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+}
+
+func (r *recorder) Write(p []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+
+	return r.body.Write(p)
+}
+
+// Handler wraps the given net/http.Handler, so that successful responses made
+// from a cached result, as recorded by SetExpires, are given an ETag of their
+// body and a Cache-Control max-age of the time left until the result expires.
+// Requests with an If-None-Match header matching the ETag are responded to
+// with a 304 Not Modified instead.
+func Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		e := new(expires)
+		rec := &recorder{ResponseWriter: w}
+		next.ServeHTTP(rec, r.WithContext(context.WithValue(r.Context(), contextKeyExpires, e)))
+
+		if rec.status == 0 {
+			rec.status = http.StatusOK
+		}
+
+		if t := e.get(); rec.status == http.StatusOK && !t.IsZero() {
+			maxAge := int(time.Until(t) / time.Second)
+			if maxAge < 0 {
+				maxAge = 0
+			}
+
+			h := w.Header()
+			etag := representationETag(h.Get("Content-Encoding"), rec.body.Bytes())
+			h.Set("ETag", etag)
+			h.Set("Cache-Control", "max-age="+strconv.Itoa(maxAge))
+
+			if Match(r.Header.Get("If-None-Match"), etag) {
+				h.Del("Content-Type")
+				h.Del("Content-Length")
+				h.Del("Content-Encoding")
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+		}
+
+		w.WriteHeader(rec.status)
+		w.Write(rec.body.Bytes())
+	})
+}
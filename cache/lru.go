@@ -0,0 +1,128 @@
+// Package cache implements the least recently used cache, and the HTTP
+// validators, used by the generated caching middleware.
+//
+// Results of a method are cached when it is declared with a cache
+// annotation, optionally followed by how long its results are cached for:
+//
+//	// @cache 30s
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// Entry represents a cached value, along with when it expires.
+type Entry struct {
+	Value   interface{}
+	Expires time.Time
+}
+
+type element struct {
+	key   string
+	entry Entry
+}
+
+// LRU is a cache holding up to a maximum number of entries, which expire
+// after a fixed TTL.  When full, the least recently used entry is evicted to
+// make room for another.  It is safe for concurrent use.
+type LRU struct {
+	maxEntries int
+	ttl        time.Duration
+
+	mu       sync.Mutex
+	order    *list.List
+	elements map[string]*list.Element
+}
+
+// New returns an LRU holding up to maxEntries entries, each of which expire
+// ttl after they're added.  If maxEntries is zero or less, the number of
+// entries is unbounded.
+func New(maxEntries int, ttl time.Duration) *LRU {
+	return &LRU{
+		maxEntries: maxEntries,
+		ttl:        ttl,
+		order:      list.New(),
+		elements:   make(map[string]*list.Element),
+	}
+}
+
+// Get returns the Entry with the given key, if it's present and has not yet
+// expired.
+func (l *LRU) Get(key string) (Entry, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	el, ok := l.elements[key]
+	if !ok {
+		return Entry{}, false
+	}
+
+	e := el.Value.(*element)
+	if !time.Now().Before(e.entry.Expires) {
+		l.remove(el)
+		return Entry{}, false
+	}
+
+	l.order.MoveToFront(el)
+	return e.entry, true
+}
+
+// Add caches the value with the given key, replacing any existing Entry, and
+// returns the new Entry.
+func (l *LRU) Add(key string, value interface{}) Entry {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	entry := Entry{
+		Value:   value,
+		Expires: time.Now().Add(l.ttl),
+	}
+
+	if el, ok := l.elements[key]; ok {
+		el.Value.(*element).entry = entry
+		l.order.MoveToFront(el)
+		return entry
+	}
+
+	l.elements[key] = l.order.PushFront(&element{key: key, entry: entry})
+	if l.maxEntries > 0 && l.order.Len() > l.maxEntries {
+		l.remove(l.order.Back())
+	}
+
+	return entry
+}
+
+// Remove removes the Entry with the given key, if present.
+func (l *LRU) Remove(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if el, ok := l.elements[key]; ok {
+		l.remove(el)
+	}
+}
+
+// Purge removes every Entry.
+func (l *LRU) Purge() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.order.Init()
+	l.elements = make(map[string]*list.Element)
+}
+
+// Len returns the number of entries held, including those that have expired
+// but have yet to be removed.
+func (l *LRU) Len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.order.Len()
+}
+
+func (l *LRU) remove(el *list.Element) {
+	l.order.Remove(el)
+	delete(l.elements, el.Value.(*element).key)
+}
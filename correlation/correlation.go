@@ -0,0 +1,119 @@
+// Package correlation implements the propagation of request IDs, also known
+// as correlation IDs, between the generated clients and servers, so the log
+// lines of a request may be correlated across services.
+//
+// Request IDs are held within the context by
+// github.com/ayiga/go-kit-middlewarer/logs.WithRequestID, from which the
+// generated logging and slog middlewares log them.
+package correlation
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+
+	"github.com/ayiga/go-kit-middlewarer/logs"
+)
+
+// Header is the header request IDs are propagated within.
+const Header = "X-Request-ID"
+
+// MaxLength is the maximum length of an accepted request ID.
+const MaxLength = 128
+
+// New returns a new, random, request ID.
+func New() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(err)
+	}
+
+	return hex.EncodeToString(b[:])
+}
+
+// Valid reports whether the given request ID is acceptable.  Request IDs must
+// be no longer than MaxLength, and consist of letters, digits, and the
+// characters - _ . : only, so they're safe to log.
+func Valid(id string) bool {
+	if id == "" || len(id) > MaxLength {
+		return false
+	}
+
+	for _, c := range id {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '-', c == '_', c == '.', c == ':':
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+// HTTPToContext is a github.com/go-kit/kit/transport/http.RequestFunc that
+// stores the request ID of the incoming Request within the context.  If the
+// Request has no valid request ID, a New one is created.  A request ID already
+// stored within the context, such as by Handler, is kept.
+func HTTPToContext(ctx context.Context, r *http.Request) context.Context {
+	if _, ok := logs.RequestID(ctx); ok {
+		return ctx
+	}
+
+	id := r.Header.Get(Header)
+	if !Valid(id) {
+		id = New()
+	}
+
+	return logs.WithRequestID(ctx, id)
+}
+
+// Handler wraps the given net/http.Handler, so that the request ID of each
+// Request, or a New one if it has no valid request ID, is stored within its
+// context and returned to the client before the Request is served.  Unlike
+// ContextToHTTPResponse, which go-kit doesn't run when an endpoint fails, the
+// request ID is returned with error responses as well.
+func Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := HTTPToContext(r.Context(), r)
+		id, _ := logs.RequestID(ctx)
+
+		w.Header().Set(Header, id)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// ContextToHTTPResponse is a
+// github.com/go-kit/kit/transport/http.ServerResponseFunc that returns the
+// request ID stored within the context to the client.  It isn't run for
+// requests that fail, so Handler is to be preferred.
+func ContextToHTTPResponse(ctx context.Context, w http.ResponseWriter) context.Context {
+	if id, ok := logs.RequestID(ctx); ok {
+		w.Header().Set(Header, id)
+	}
+
+	return ctx
+}
+
+// ContextToHTTP is a github.com/go-kit/kit/transport/http.RequestFunc that
+// forwards the request ID stored within the context with the outgoing
+// Request.
+func ContextToHTTP(ctx context.Context, r *http.Request) context.Context {
+	if id, ok := logs.RequestID(ctx); ok {
+		r.Header.Set(Header, id)
+	}
+
+	return ctx
+}
+
+// Ensure returns ctx and the request ID held by it, or, if it has none, a
+// copy of ctx holding a New one.
+func Ensure(ctx context.Context) (context.Context, string) {
+	if id, ok := logs.RequestID(ctx); ok {
+		return ctx, id
+	}
+
+	id := New()
+	return logs.WithRequestID(ctx, id), id
+}
@@ -0,0 +1,101 @@
+package correlation_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	httptransport "github.com/go-kit/kit/transport/http"
+
+	"github.com/ayiga/go-kit-middlewarer/correlation"
+	"github.com/ayiga/go-kit-middlewarer/logs"
+)
+
+func TestValid(t *testing.T) {
+	cases := map[string]bool{
+		"":                        false,
+		"abc-123_DEF.4:5":         true,
+		"has space":               false,
+		"new\nline":               false,
+		strings.Repeat("a", 128):  true,
+		strings.Repeat("a", 129):  false,
+		"0f8c2e6a-8a4b-4c1e-9e7d": true,
+	}
+
+	for id, want := range cases {
+		if got := correlation.Valid(id); got != want {
+			t.Errorf("Valid(%q):\ngot:\n\t%t\nwant:\n\t%t", id, got, want)
+		}
+	}
+}
+
+func TestHTTPToContext(t *testing.T) {
+	r := httptest.NewRequest("GET", "/count", nil)
+	r.Header.Set(correlation.Header, "abc")
+	if id, _ := logs.RequestID(correlation.HTTPToContext(context.Background(), r)); id != "abc" {
+		t.Errorf("RequestID:\ngot:\n\t%q\nwant:\n\t%q", id, "abc")
+	}
+
+	r.Header.Set(correlation.Header, "bad id")
+	id, ok := logs.RequestID(correlation.HTTPToContext(context.Background(), r))
+	if !ok || id == "bad id" || !correlation.Valid(id) {
+		t.Errorf("RequestID: expected a new request ID to replace an invalid one, got %q", id)
+	}
+}
+
+func TestPropagation(t *testing.T) {
+	ctx, id := correlation.Ensure(context.Background())
+	if _, again := correlation.Ensure(ctx); again != id {
+		t.Errorf("Ensure:\ngot:\n\t%q\nwant:\n\t%q", again, id)
+	}
+
+	r, _ := http.NewRequest("GET", "http://localhost/count", nil)
+	correlation.ContextToHTTP(ctx, r)
+	if got := r.Header.Get(correlation.Header); got != id {
+		t.Errorf("ContextToHTTP:\ngot:\n\t%q\nwant:\n\t%q", got, id)
+	}
+
+	w := httptest.NewRecorder()
+	correlation.ContextToHTTPResponse(correlation.HTTPToContext(context.Background(), r), w)
+	if got := w.Header().Get(correlation.Header); got != id {
+		t.Errorf("ContextToHTTPResponse:\ngot:\n\t%q\nwant:\n\t%q", got, id)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	var served string
+	failing := httptransport.NewServer(
+		func(ctx context.Context, request interface{}) (interface{}, error) {
+			served, _ = logs.RequestID(ctx)
+			return nil, errors.New("failed")
+		},
+		func(context.Context, *http.Request) (interface{}, error) { return nil, nil },
+		func(context.Context, http.ResponseWriter, interface{}) error { return nil },
+		httptransport.ServerBefore(correlation.HTTPToContext),
+	)
+	h := correlation.Handler(failing)
+
+	// the request ID is returned even though the endpoint fails
+	r := httptest.NewRequest("GET", "/count", nil)
+	r.Header.Set(correlation.Header, "abc")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("Status:\ngot:\n\t%d\nwant:\n\t%d", w.Code, http.StatusInternalServerError)
+	}
+	if got := w.Header().Get(correlation.Header); got != "abc" || served != "abc" {
+		t.Errorf("Request ID:\ngot:\n\t%q served %q\nwant:\n\t%q", got, served, "abc")
+	}
+
+	// a new request ID is the same one the endpoint is served with
+	r = httptest.NewRequest("GET", "/count", nil)
+	r.Header.Set(correlation.Header, "bad id")
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if got := w.Header().Get(correlation.Header); !correlation.Valid(got) || got != served {
+		t.Errorf("Request ID:\ngot:\n\t%q\nwant:\n\t%q", got, served)
+	}
+}
@@ -0,0 +1,141 @@
+package credentials
+
+import (
+	"context"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/ayiga/go-kit-middlewarer/encoding"
+)
+
+func init() {
+	gob.Register(ForbiddenError{})
+	encoding.RegisterError(ForbiddenError{})
+	encoding.RegisterSentinel("github.com/ayiga/go-kit-middlewarer/credentials.ErrForbidden", ErrForbidden)
+}
+
+// ErrForbidden is returned by an Authorizer, or wrapped within the error it
+// returns, to deny a request.
+var ErrForbidden = errors.New("forbidden")
+
+// ForbiddenError is returned when a request has been denied by an
+// Authorizer, or by the Requirement of its method.  It is responded with as a
+// 403 Forbidden, and unwraps to ErrForbidden.
+type ForbiddenError struct {
+	Path    string `json:"path" xml:"path"`
+	Message string `json:"message" xml:"message"`
+}
+
+func (e ForbiddenError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Path, e.Message)
+}
+
+// Unwrap allows errors.Is to match ErrForbidden
+func (e ForbiddenError) Unwrap() error {
+	return ErrForbidden
+}
+
+// StatusCode implements github.com/go-kit/kit/transport/http.StatusCoder
+func (e ForbiddenError) StatusCode() int {
+	return http.StatusForbidden
+}
+
+// Authorizer decides whether the Principal of a request may call the method
+// with the given path, with the given decoded Request.  It returns nil to
+// allow the request, and ErrForbidden, or an error wrapping it, to deny it.
+// Any other error is returned as is, such as when the policy is unavailable.
+//
+// The path is one of the generated endpoint package's Path constants, and the
+// Principal is the zero Principal if the request was not authenticated.
+type Authorizer interface {
+	Authorize(ctx context.Context, path string, request interface{}, p Principal) error
+}
+
+// AuthorizerFunc is an adapter allowing an ordinary function to be used as an
+// Authorizer.
+type AuthorizerFunc func(ctx context.Context, path string, request interface{}, p Principal) error
+
+// Authorize implements Authorizer
+func (f AuthorizerFunc) Authorize(ctx context.Context, path string, request interface{}, p Principal) error {
+	return f(ctx, path, request, p)
+}
+
+// Requirement represents what a Principal must have been granted to call a
+// method, as declared by its scope and role annotations:
+//
+//	// @scope strings:write
+//	// @role admin editor
+type Requirement struct {
+	// Scopes must all have been granted.
+	Scopes []string
+
+	// Roles, if any, must have at least one of them granted.
+	Roles []string
+}
+
+// Check returns an error wrapping ErrForbidden if the Principal does not meet
+// the Requirement.
+func (r Requirement) Check(p Principal) error {
+	for _, s := range r.Scopes {
+		if !p.HasScope(s) {
+			return fmt.Errorf("%w: the %s scope is required", ErrForbidden, s)
+		}
+	}
+
+	if len(r.Roles) == 0 {
+		return nil
+	}
+
+	for _, role := range r.Roles {
+		if p.HasRole(role) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: one of the %s roles is required", ErrForbidden, strings.Join(r.Roles, ", "))
+}
+
+// Authorize checks the Principal stored within ctx by Authenticate against
+// the Requirement of the method with the given path, and then consults the
+// Authorizer, if any.  Denials are returned as a ForbiddenError.
+func Authorize(ctx context.Context, path string, request interface{}, r Requirement, a Authorizer) error {
+	p, _ := FromContext(ctx)
+
+	err := r.Check(p)
+	if err == nil && a != nil {
+		err = a.Authorize(ctx, path, request, p)
+	}
+
+	var fe ForbiddenError
+	switch {
+	case err == nil || errors.As(err, &fe):
+		return err
+	case errors.Is(err, ErrForbidden):
+		return ForbiddenError{Path: path, Message: err.Error()}
+	default:
+		return err
+	}
+}
+
+// Argument returns the argument with the given parameter name from a decoded
+// Request of the generated HTTP transport, for use by Authorizers.
+func Argument(request interface{}, name string) (interface{}, bool) {
+	v := reflect.Indirect(reflect.ValueOf(request))
+	if v.Kind() != reflect.Struct {
+		return nil, false
+	}
+
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath == "" && strings.Split(f.Tag.Get("json"), ",")[0] == name {
+			return v.Field(i).Interface(), true
+		}
+	}
+
+	return nil, false
+}
@@ -0,0 +1,235 @@
+// Package credentials implements the verification of the credentials
+// presented with a request, and their attachment by clients, for the
+// generated auth layers, along with the authorization of the Principal they
+// establish, for the generated authorization layer.
+//
+// JSON Web Tokens, static API keys, and basic auth are supported.  The schemes
+// a method accepts are declared with an auth annotation, using the names of
+// their Verifiers, or none for methods that don't require credentials at all:
+//
+//	// @auth jwt apikey
+package credentials
+
+import (
+	"context"
+	"encoding/gob"
+	"errors"
+	"net/http"
+
+	"github.com/ayiga/go-kit-middlewarer/encoding"
+)
+
+func init() {
+	gob.Register(UnauthorizedError{})
+	encoding.RegisterError(UnauthorizedError{})
+}
+
+var (
+	// ErrNoCredentials is returned by a Verifier when the credentials of its
+	// scheme were not presented.
+	ErrNoCredentials = errors.New("no credentials were presented")
+
+	// ErrInvalidCredentials is returned by a Verifier when the credentials of
+	// its scheme were presented, but failed verification.
+	ErrInvalidCredentials = errors.New("invalid credentials")
+)
+
+// APIKeyHeader is the header API keys are presented within.
+const APIKeyHeader = "X-API-Key"
+
+// Credentials represents the credentials presented with a request.
+type Credentials struct {
+	// Authorization is the value of the Authorization header, which holds
+	// bearer tokens and basic auth.
+	Authorization string
+
+	// APIKey is the value of the APIKeyHeader.
+	APIKey string
+}
+
+// Principal represents who a request was made by, as established by a
+// Verifier.
+type Principal struct {
+	// Subject identifies who the request was made by, such as the sub claim
+	// of a JWT, or the user of basic auth.
+	Subject string
+
+	// Scheme is the scheme of the Verifier that established the Principal.
+	Scheme string
+
+	// Scopes and Roles are what the Principal has been granted, for use when
+	// authorizing requests.
+	Scopes []string
+	Roles  []string
+
+	// Claims are the claims of a JWT, if the Principal was established by
+	// one.
+	Claims map[string]interface{}
+}
+
+// HasScope reports whether the Principal has been granted the given scope.
+func (p Principal) HasScope(scope string) bool {
+	return contains(p.Scopes, scope)
+}
+
+// HasRole reports whether the Principal has been granted the given role.
+func (p Principal) HasRole(role string) bool {
+	return contains(p.Roles, role)
+}
+
+// Verifier verifies the credentials of a single scheme.
+type Verifier interface {
+	// Scheme returns the name of the scheme, as used by auth annotations.
+	Scheme() string
+
+	// Challenge returns the WWW-Authenticate challenge of the scheme, or an
+	// empty string if it has none.
+	Challenge() string
+
+	// Verify returns the Principal the Credentials were presented by.  It
+	// returns ErrNoCredentials if the Credentials of the scheme were not
+	// presented, and an error wrapping ErrInvalidCredentials if they fail
+	// verification.
+	Verify(ctx context.Context, c Credentials) (Principal, error)
+}
+
+// Attacher produces the credentials of a single scheme, for a client to
+// present with its requests.
+type Attacher interface {
+	// Scheme returns the name of the scheme, as used by auth annotations.
+	Scheme() string
+
+	// Credentials returns the Credentials to present with a request.
+	Credentials(ctx context.Context) (Credentials, error)
+}
+
+type contextKey int
+
+const (
+	contextKeyPrincipal contextKey = iota
+	contextKeyIncoming
+	contextKeyOutgoing
+)
+
+// NewContext returns a copy of ctx holding the given Principal.
+func NewContext(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, contextKeyPrincipal, p)
+}
+
+// FromContext returns the Principal held by ctx, if any.
+func FromContext(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(contextKeyPrincipal).(Principal)
+	return p, ok
+}
+
+// HTTPToContext is a github.com/go-kit/kit/transport/http.RequestFunc that
+// stores the Credentials of the incoming Request within the context, for
+// Authenticate to verify.
+func HTTPToContext(ctx context.Context, r *http.Request) context.Context {
+	return context.WithValue(ctx, contextKeyIncoming, Credentials{
+		Authorization: r.Header.Get("Authorization"),
+		APIKey:        r.Header.Get(APIKeyHeader),
+	})
+}
+
+// WithCredentials returns a copy of ctx holding the Credentials to present
+// with an outgoing Request, by ContextToHTTP.
+func WithCredentials(ctx context.Context, c Credentials) context.Context {
+	return context.WithValue(ctx, contextKeyOutgoing, c)
+}
+
+// ContextToHTTP is a github.com/go-kit/kit/transport/http.RequestFunc that
+// sets the headers of the outgoing Request to the Credentials stored within
+// the context by WithCredentials.
+func ContextToHTTP(ctx context.Context, r *http.Request) context.Context {
+	c, ok := ctx.Value(contextKeyOutgoing).(Credentials)
+	if !ok {
+		return ctx
+	}
+
+	if c.Authorization != "" {
+		r.Header.Set("Authorization", c.Authorization)
+	}
+	if c.APIKey != "" {
+		r.Header.Set(APIKeyHeader, c.APIKey)
+	}
+
+	return ctx
+}
+
+// Authenticate verifies the Credentials stored within ctx by HTTPToContext,
+// with the first of the Verifiers whose scheme they were presented for.  If
+// no Credentials were presented for any of them, or they fail verification,
+// an UnauthorizedError is returned.
+func Authenticate(ctx context.Context, verifiers []Verifier) (Principal, error) {
+	c, _ := ctx.Value(contextKeyIncoming).(Credentials)
+	for _, v := range verifiers {
+		p, err := v.Verify(ctx, c)
+		if errors.Is(err, ErrNoCredentials) {
+			continue
+		}
+
+		if err != nil {
+			return Principal{}, UnauthorizedError{
+				Message:    err.Error(),
+				Challenges: challenges(verifiers),
+			}
+		}
+
+		p.Scheme = v.Scheme()
+		return p, nil
+	}
+
+	return Principal{}, UnauthorizedError{
+		Message:    ErrNoCredentials.Error(),
+		Challenges: challenges(verifiers),
+	}
+}
+
+func challenges(verifiers []Verifier) []string {
+	var result []string
+	for _, v := range verifiers {
+		if c := v.Challenge(); c != "" {
+			result = append(result, c)
+		}
+	}
+
+	return result
+}
+
+// UnauthorizedError is returned when a request has been rejected, as it was
+// made without acceptable credentials.  It is responded with as a 401
+// Unauthorized, with a WWW-Authenticate header for each of the Challenges.
+type UnauthorizedError struct {
+	Message    string   `json:"message" xml:"message"`
+	Challenges []string `json:"challenges,omitempty" xml:"challenge"`
+}
+
+func (e UnauthorizedError) Error() string {
+	return "unauthorized: " + e.Message
+}
+
+// StatusCode implements github.com/go-kit/kit/transport/http.StatusCoder
+func (e UnauthorizedError) StatusCode() int {
+	return http.StatusUnauthorized
+}
+
+// Headers implements github.com/go-kit/kit/transport/http.Headerer
+func (e UnauthorizedError) Headers() http.Header {
+	h := make(http.Header)
+	for _, c := range e.Challenges {
+		h.Add("WWW-Authenticate", c)
+	}
+
+	return h
+}
+
+func contains(slice []string, entry string) bool {
+	for _, s := range slice {
+		if s == entry {
+			return true
+		}
+	}
+
+	return false
+}
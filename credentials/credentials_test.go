@@ -0,0 +1,237 @@
+package credentials_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+
+	"github.com/ayiga/go-kit-middlewarer/credentials"
+	"github.com/ayiga/go-kit-middlewarer/encoding"
+)
+
+var hmacKey = []byte("secret")
+
+// incoming returns the context a server has after receiving a request with
+// the credentials of the given Attacher.
+func incoming(t *testing.T, a credentials.Attacher) context.Context {
+	c, err := a.Credentials(context.Background())
+	if err != nil {
+		t.Fatalf("Unable to produce credentials: %s", err)
+	}
+
+	r := httptest.NewRequest(http.MethodPost, "/", nil)
+	credentials.ContextToHTTP(credentials.WithCredentials(context.Background(), c), r)
+	return credentials.HTTPToContext(context.Background(), r)
+}
+
+func signer(method jwt.SigningMethod, kid string, key interface{}, claims jwt.MapClaims) credentials.JWTSigner {
+	return credentials.JWTSigner{
+		KeyID:  kid,
+		Key:    key,
+		Method: method,
+		Claims: func(context.Context) jwt.Claims { return claims },
+	}
+}
+
+func TestJWT(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Unable to generate an RSA key: %s", err)
+	}
+
+	verifier := credentials.JWT{
+		Keys:     credentials.KeySet{"": hmacKey, "rsa": &rsaKey.PublicKey},
+		Audience: "stringsvc",
+	}
+
+	claims := jwt.MapClaims{
+		"sub":   "alice",
+		"aud":   "stringsvc",
+		"scope": "read write",
+		"roles": []interface{}{"admin"},
+		"exp":   time.Now().Add(time.Minute).Unix(),
+	}
+
+	for _, a := range []credentials.Attacher{
+		signer(jwt.SigningMethodHS256, "", hmacKey, claims),
+		signer(jwt.SigningMethodRS256, "rsa", rsaKey, claims),
+	} {
+		p, err := credentials.Authenticate(incoming(t, a), []credentials.Verifier{verifier})
+		if err != nil {
+			t.Fatalf("Authenticate: %s", err)
+		}
+
+		if p.Subject != "alice" || p.Scheme != "jwt" || !p.HasScope("write") || !p.HasRole("admin") {
+			t.Errorf("Unexpected Principal: %#v", p)
+		}
+	}
+
+	expired := jwt.MapClaims{"sub": "alice", "aud": "stringsvc", "exp": time.Now().Add(-time.Minute).Unix()}
+	otherAudience := jwt.MapClaims{"sub": "alice", "aud": "other"}
+	for _, a := range []credentials.Attacher{
+		signer(jwt.SigningMethodHS256, "", hmacKey, expired),
+		signer(jwt.SigningMethodHS256, "", hmacKey, otherAudience),
+		signer(jwt.SigningMethodHS256, "", []byte("wrong"), claims),
+		// an HMAC token must not be verified with the RSA public key
+		signer(jwt.SigningMethodHS256, "rsa", publicKeyBytes(rsaKey), claims),
+		credentials.BearerToken("garbage"),
+	} {
+		_, err := credentials.Authenticate(incoming(t, a), []credentials.Verifier{verifier})
+		var ue credentials.UnauthorizedError
+		if !errors.As(err, &ue) {
+			t.Errorf("Authenticate:\ngot:\n\t%v\nwant:\n\t%T", err, ue)
+		}
+	}
+}
+
+func publicKeyBytes(key *rsa.PrivateKey) []byte {
+	return key.PublicKey.N.Bytes()
+}
+
+func TestAPIKeysAndBasic(t *testing.T) {
+	verifiers := []credentials.Verifier{
+		credentials.APIKeys{"key-1": {Subject: "batch", Scopes: []string{"read"}}},
+		credentials.BasicUsers("stringsvc", map[string]string{"bob": "hunter2"}),
+	}
+
+	tests := []struct {
+		attacher credentials.Attacher
+		subject  string
+		scheme   string
+	}{
+		{credentials.APIKey("key-1"), "batch", "apikey"},
+		{credentials.BasicAuth{User: "bob", Password: "hunter2"}, "bob", "basic"},
+		{credentials.APIKey("key-2"), "", ""},
+		{credentials.BasicAuth{User: "bob", Password: "hunter3"}, "", ""},
+	}
+
+	for _, test := range tests {
+		p, err := credentials.Authenticate(incoming(t, test.attacher), verifiers)
+		if test.subject == "" {
+			if err == nil {
+				t.Errorf("Authenticate(%#v): expected an error", test.attacher)
+			}
+			continue
+		}
+
+		if err != nil || p.Subject != test.subject || p.Scheme != test.scheme {
+			t.Errorf("Authenticate(%#v):\ngot:\n\t%s %s %v\nwant:\n\t%s %s", test.attacher, p.Subject, p.Scheme, err, test.subject, test.scheme)
+		}
+	}
+}
+
+func TestNoCredentials(t *testing.T) {
+	ctx := credentials.HTTPToContext(context.Background(), httptest.NewRequest(http.MethodPost, "/", nil))
+	_, err := credentials.Authenticate(ctx, []credentials.Verifier{
+		credentials.JWT{},
+		credentials.BasicUsers("stringsvc", nil),
+	})
+
+	want := credentials.UnauthorizedError{
+		Message:    credentials.ErrNoCredentials.Error(),
+		Challenges: []string{"Bearer", `Basic realm="stringsvc"`},
+	}
+	if !reflect.DeepEqual(err, want) {
+		t.Fatalf("Authenticate:\ngot:\n\t%#v\nwant:\n\t%#v", err, want)
+	}
+
+	if got, want := encoding.ErrorStatus(err), http.StatusUnauthorized; got != want {
+		t.Errorf("ErrorStatus:\ngot:\n\t%d\nwant:\n\t%d", got, want)
+	}
+	if got := want.Headers()["Www-Authenticate"]; !reflect.DeepEqual(got, want.Challenges) {
+		t.Errorf("Headers:\ngot:\n\t%q\nwant:\n\t%q", got, want.Challenges)
+	}
+}
+
+type uppercaseRequest struct {
+	Str    string `json:"str" xml:"str"`
+	Locale string `json:"locale" xml:"locale"`
+}
+
+func TestAuthorize(t *testing.T) {
+	// deny anyone but alice uppercasing in French
+	authorizer := credentials.AuthorizerFunc(func(ctx context.Context, path string, request interface{}, p credentials.Principal) error {
+		if locale, _ := credentials.Argument(request, "locale"); locale == "fr" && p.Subject != "alice" {
+			return fmt.Errorf("%w: only alice speaks French", credentials.ErrForbidden)
+		}
+
+		return nil
+	})
+
+	requirement := credentials.Requirement{Scopes: []string{"write"}, Roles: []string{"admin", "editor"}}
+	bob := credentials.Principal{Subject: "bob", Scopes: []string{"write"}, Roles: []string{"editor"}}
+	alice := bob
+	alice.Subject = "alice"
+
+	tests := []struct {
+		p      credentials.Principal
+		locale string
+		want   string
+	}{
+		{bob, "en", ""},
+		{alice, "fr", ""},
+		{bob, "fr", "/uppercase: forbidden: only alice speaks French"},
+		{credentials.Principal{Subject: "eve", Roles: []string{"admin"}}, "en", "/uppercase: forbidden: the write scope is required"},
+		{credentials.Principal{Subject: "eve", Scopes: []string{"write"}}, "en", "/uppercase: forbidden: one of the admin, editor roles is required"},
+	}
+
+	for _, test := range tests {
+		ctx := credentials.NewContext(context.Background(), test.p)
+		err := credentials.Authorize(ctx, "/uppercase", &uppercaseRequest{Str: "abc", Locale: test.locale}, requirement, authorizer)
+		if test.want == "" {
+			if err != nil {
+				t.Errorf("Authorize(%s, %s): %s", test.p.Subject, test.locale, err)
+			}
+			continue
+		}
+
+		var fe credentials.ForbiddenError
+		if !errors.As(err, &fe) || err.Error() != test.want {
+			t.Errorf("Authorize(%s, %s):\ngot:\n\t%#v\nwant:\n\t%s", test.p.Subject, test.locale, err, test.want)
+		}
+		if !errors.Is(err, credentials.ErrForbidden) || encoding.ErrorStatus(err) != http.StatusForbidden {
+			t.Errorf("Authorize(%s, %s): expected a 403 matching ErrForbidden", test.p.Subject, test.locale)
+		}
+	}
+
+	unavailable := errors.New("the policy is unavailable")
+	failing := credentials.AuthorizerFunc(func(context.Context, string, interface{}, credentials.Principal) error {
+		return unavailable
+	})
+	if err := credentials.Authorize(context.Background(), "/uppercase", nil, credentials.Requirement{}, failing); err != unavailable {
+		t.Errorf("Authorize:\ngot:\n\t%v\nwant:\n\t%v", err, unavailable)
+	}
+}
+
+func TestForbiddenErrorRoundTrip(t *testing.T) {
+	sent := credentials.ForbiddenError{Path: "/uppercase", Message: "forbidden: the write scope is required"}
+
+	rec := httptest.NewRecorder()
+	encoding.MakeErrorEncoder(encoding.Default())(context.Background(), sent, rec)
+	if got, want := rec.Code, http.StatusForbidden; got != want {
+		t.Errorf("Status:\ngot:\n\t%d\nwant:\n\t%d", got, want)
+	}
+
+	r, err := encoding.Default().DecodeResponse(new(uppercaseRequest))(context.Background(), rec.Result())
+	if err != nil {
+		t.Fatalf("Unable to Decode Response: %s", err)
+	}
+
+	// the client receives the typed error, which still matches ErrForbidden
+	fe, ok := r.(credentials.ForbiddenError)
+	if !ok || fe != sent {
+		t.Fatalf("Error:\ngot:\n\t%#v\nwant:\n\t%#v", r, sent)
+	}
+	if !errors.Is(fe, credentials.ErrForbidden) {
+		t.Errorf("errors.Is(err, ErrForbidden) = false")
+	}
+}
@@ -0,0 +1,169 @@
+package credentials
+
+import (
+	"context"
+	"crypto/rsa"
+	"fmt"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// KeySet holds the keys JSON Web Tokens are verified with, keyed by the kid
+// of their header.  The key of the empty kid verifies tokens without one.
+//
+// HMAC keys, for the HS algorithms, are a []byte, and RSA keys, for the RS
+// algorithms, are an *rsa.PublicKey.  Tokens are only accepted when their
+// algorithm matches the type of their key.
+type KeySet map[string]interface{}
+
+// JWT is a Verifier of JSON Web Tokens, presented as a bearer token within
+// the Authorization header.  The exp, nbf, and iat claims are always
+// verified, when present.
+type JWT struct {
+	// Keys are the keys tokens are verified with.
+	Keys KeySet
+
+	// Audience, if set, must be within the aud claim of the token.
+	Audience string
+
+	// Issuer, if set, must be the iss claim of the token.
+	Issuer string
+}
+
+// Scheme implements Verifier
+func (JWT) Scheme() string {
+	return "jwt"
+}
+
+// Challenge implements Verifier
+func (JWT) Challenge() string {
+	return "Bearer"
+}
+
+// Verify implements Verifier.  The Principal's Subject is the sub claim of
+// the token, its Scopes are the space separated scope claim, and its Roles
+// are the roles claim.
+func (j JWT) Verify(_ context.Context, c Credentials) (Principal, error) {
+	token, ok := cutScheme(c.Authorization, "Bearer")
+	if !ok {
+		return Principal{}, ErrNoCredentials
+	}
+
+	claims := jwt.MapClaims{}
+	if _, err := jwt.ParseWithClaims(token, claims, j.key); err != nil {
+		return Principal{}, fmt.Errorf("%w: %s", ErrInvalidCredentials, err)
+	}
+
+	if j.Audience != "" && !claims.VerifyAudience(j.Audience, true) {
+		return Principal{}, fmt.Errorf("%w: the token is not intended for %s", ErrInvalidCredentials, j.Audience)
+	}
+	if j.Issuer != "" && !claims.VerifyIssuer(j.Issuer, true) {
+		return Principal{}, fmt.Errorf("%w: the token was not issued by %s", ErrInvalidCredentials, j.Issuer)
+	}
+
+	sub, _ := claims["sub"].(string)
+	scope, _ := claims["scope"].(string)
+	return Principal{
+		Subject: sub,
+		Scopes:  strings.Fields(scope),
+		Roles:   stringsClaim(claims["roles"]),
+		Claims:  claims,
+	}, nil
+}
+
+// key is the github.com/golang-jwt/jwt/v4.Keyfunc of the KeySet.
+func (j JWT) key(t *jwt.Token) (interface{}, error) {
+	kid, _ := t.Header["kid"].(string)
+	key, ok := j.Keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown key %q", kid)
+	}
+
+	switch t.Method.(type) {
+	case *jwt.SigningMethodHMAC:
+		if k, ok := key.([]byte); ok {
+			return k, nil
+		}
+	case *jwt.SigningMethodRSA:
+		if k, ok := key.(*rsa.PublicKey); ok {
+			return k, nil
+		}
+	}
+
+	return nil, fmt.Errorf("the %s algorithm is not allowed for key %q", t.Method.Alg(), kid)
+}
+
+func stringsClaim(claim interface{}) []string {
+	values, _ := claim.([]interface{})
+	var result []string
+	for _, v := range values {
+		if s, ok := v.(string); ok {
+			result = append(result, s)
+		}
+	}
+
+	return result
+}
+
+// BearerToken is an Attacher presenting a fixed token, such as a JWT issued
+// to the client, as a bearer token.
+type BearerToken string
+
+// Scheme implements Attacher
+func (BearerToken) Scheme() string {
+	return "jwt"
+}
+
+// Credentials implements Attacher
+func (t BearerToken) Credentials(context.Context) (Credentials, error) {
+	return Credentials{Authorization: "Bearer " + string(t)}, nil
+}
+
+// JWTSigner is an Attacher presenting a JWT it signs for every request, as a
+// bearer token.
+type JWTSigner struct {
+	// KeyID is the kid of the token's header, if set.
+	KeyID string
+
+	// Key is the key the token is signed with, such as a []byte for HMAC, or
+	// an *rsa.PrivateKey for RSA.
+	Key interface{}
+
+	// Method is the algorithm the token is signed with, such as
+	// github.com/golang-jwt/jwt/v4.SigningMethodHS256.
+	Method jwt.SigningMethod
+
+	// Claims returns the claims of the token for the request being made.
+	Claims func(ctx context.Context) jwt.Claims
+}
+
+// Scheme implements Attacher
+func (JWTSigner) Scheme() string {
+	return "jwt"
+}
+
+// Credentials implements Attacher
+func (s JWTSigner) Credentials(ctx context.Context) (Credentials, error) {
+	token := jwt.NewWithClaims(s.Method, s.Claims(ctx))
+	if s.KeyID != "" {
+		token.Header["kid"] = s.KeyID
+	}
+
+	signed, err := token.SignedString(s.Key)
+	if err != nil {
+		return Credentials{}, err
+	}
+
+	return Credentials{Authorization: "Bearer " + signed}, nil
+}
+
+// cutScheme returns the credentials of an Authorization header using the
+// given scheme, which is matched case insensitively.
+func cutScheme(authorization, scheme string) (string, bool) {
+	if len(authorization) <= len(scheme) || !strings.EqualFold(authorization[:len(scheme)], scheme) || authorization[len(scheme)] != ' ' {
+		return "", false
+	}
+
+	return strings.TrimSpace(authorization[len(scheme)+1:]), true
+}
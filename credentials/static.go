@@ -0,0 +1,131 @@
+package credentials
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strings"
+)
+
+// APIKeys is a Verifier of static API keys, presented within the
+// APIKeyHeader, mapping each key to the Principal it was issued to.
+type APIKeys map[string]Principal
+
+// Scheme implements Verifier
+func (APIKeys) Scheme() string {
+	return "apikey"
+}
+
+// Challenge implements Verifier.  API keys have no challenge.
+func (APIKeys) Challenge() string {
+	return ""
+}
+
+// Verify implements Verifier
+func (k APIKeys) Verify(_ context.Context, c Credentials) (Principal, error) {
+	if c.APIKey == "" {
+		return Principal{}, ErrNoCredentials
+	}
+
+	for key, p := range k {
+		if subtle.ConstantTimeCompare([]byte(key), []byte(c.APIKey)) == 1 {
+			return p, nil
+		}
+	}
+
+	return Principal{}, fmt.Errorf("%w: unknown API key", ErrInvalidCredentials)
+}
+
+// Basic is a Verifier of basic auth, presented within the Authorization
+// header.
+type Basic struct {
+	// Realm is the realm of the WWW-Authenticate challenge.
+	Realm string
+
+	// Check reports whether the password is that of the user, returning the
+	// Principal of the user if it is.
+	Check func(user, password string) (Principal, bool)
+}
+
+// BasicUsers returns a Basic for a fixed set of users, mapped to their
+// passwords.  The Principal of a user has the user as its Subject.
+func BasicUsers(realm string, users map[string]string) Basic {
+	return Basic{
+		Realm: realm,
+		Check: func(user, password string) (Principal, bool) {
+			expected, ok := users[user]
+			if !ok || subtle.ConstantTimeCompare([]byte(expected), []byte(password)) != 1 {
+				return Principal{}, false
+			}
+
+			return Principal{Subject: user}, true
+		},
+	}
+}
+
+// Scheme implements Verifier
+func (Basic) Scheme() string {
+	return "basic"
+}
+
+// Challenge implements Verifier
+func (b Basic) Challenge() string {
+	return fmt.Sprintf("Basic realm=%q", b.Realm)
+}
+
+// Verify implements Verifier
+func (b Basic) Verify(_ context.Context, c Credentials) (Principal, error) {
+	encoded, ok := cutScheme(c.Authorization, "Basic")
+	if !ok {
+		return Principal{}, ErrNoCredentials
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return Principal{}, fmt.Errorf("%w: malformed basic auth", ErrInvalidCredentials)
+	}
+
+	pieces := strings.SplitN(string(decoded), ":", 2)
+	if len(pieces) != 2 {
+		return Principal{}, fmt.Errorf("%w: malformed basic auth", ErrInvalidCredentials)
+	}
+
+	p, ok := b.Check(pieces[0], pieces[1])
+	if !ok {
+		return Principal{}, fmt.Errorf("%w: incorrect user or password", ErrInvalidCredentials)
+	}
+
+	return p, nil
+}
+
+// APIKey is an Attacher presenting a static API key.
+type APIKey string
+
+// Scheme implements Attacher
+func (APIKey) Scheme() string {
+	return "apikey"
+}
+
+// Credentials implements Attacher
+func (k APIKey) Credentials(context.Context) (Credentials, error) {
+	return Credentials{APIKey: string(k)}, nil
+}
+
+// BasicAuth is an Attacher presenting a user and password as basic auth.
+type BasicAuth struct {
+	User     string
+	Password string
+}
+
+// Scheme implements Attacher
+func (BasicAuth) Scheme() string {
+	return "basic"
+}
+
+// Credentials implements Attacher
+func (b BasicAuth) Credentials(context.Context) (Credentials, error) {
+	return Credentials{
+		Authorization: "Basic " + base64.StdEncoding.EncodeToString([]byte(b.User+":"+b.Password)),
+	}, nil
+}
@@ -35,3 +35,82 @@ func TestAcceptParsing1(t *testing.T) {
 		t.Fail()
 	}
 }
+
+func negotiateRequest(t *testing.T, ctx context.Context, accept string) (string, error) {
+	r, err := http.NewRequest("GET", "/not/important", bytes.NewBuffer([]byte(string("{}"))))
+	if err != nil {
+		panic(err)
+	}
+	r.Header.Add("Content-Type", "application/json")
+	r.Header.Add("Accept", accept)
+
+	req := new(request)
+	req.embedMime = new(embedMime)
+
+	_, err = encoding.Default().DecodeRequest(req)(ctx, r)
+	return req.GetMime(), err
+}
+
+func TestAcceptWildcards(t *testing.T) {
+	tests := []struct {
+		accept string
+		want   string
+	}{
+		{"*/*", "application/json"},
+		{"application/*", "application/json"},
+		{"text/*", "text/json"},
+		{"application/*;q=0.5, application/xml", "application/xml"},
+		{"*/*;q=0.1, application/json;q=0, text/*;q=0, application/gob;q=0", "application/octet-stream+gob"},
+		{"application/xml;q=0.5, application/gob;q=0.5", "application/xml"},
+		{"application/xml, application/gob", "application/xml"},
+	}
+
+	for _, test := range tests {
+		got, err := negotiateRequest(t, context.Background(), test.accept)
+		if err != nil {
+			t.Errorf("%s: Decoding Failed: %s", test.accept, err)
+			continue
+		}
+
+		if got != test.want {
+			t.Errorf("%s:\ngot:\n\t%s\nwant:\n\t%s", test.accept, got, test.want)
+		}
+	}
+}
+
+func TestAcceptNotAcceptable(t *testing.T) {
+	_, err := negotiateRequest(t, context.Background(), "text/html, application/json;q=0")
+	if got, want := err, encoding.ErrNotAcceptable; got != want {
+		t.Fatalf("err:\ngot:\n\t%v\nwant:\n\t%v", got, want)
+	}
+
+	if got, want := encoding.ErrNotAcceptable.StatusCode(), http.StatusNotAcceptable; got != want {
+		t.Errorf("StatusCode:\ngot:\n\t%d\nwant:\n\t%d", got, want)
+	}
+}
+
+func TestAcceptServerPreference(t *testing.T) {
+	r, err := http.NewRequest("GET", "/not/important", nil)
+	if err != nil {
+		panic(err)
+	}
+
+	ctx := encoding.SetMimePreference("application/gob", "application/xml")(context.Background(), r)
+	got, err := negotiateRequest(t, ctx, "application/xml, application/json, application/gob;q=0.9")
+	if err != nil {
+		t.Fatalf("Decoding Failed: %s", err)
+	}
+
+	if want := "application/xml"; got != want {
+		t.Errorf("mime:\ngot:\n\t%s\nwant:\n\t%s", got, want)
+	}
+
+	got, err = negotiateRequest(t, ctx, "*/*")
+	if err != nil {
+		t.Fatalf("Decoding Failed: %s", err)
+	}
+
+	if want := "application/gob"; got != want {
+		t.Errorf("mime:\ngot:\n\t%s\nwant:\n\t%s", got, want)
+	}
+}
@@ -0,0 +1,26 @@
+package encoding
+
+import (
+	"io"
+	"io/ioutil"
+
+	"github.com/andybalholm/brotli"
+)
+
+func init() {
+	RegisterCompression("br", Brotli(0))
+}
+
+// Brotli is a simple brotli compressor / decompressor that conforms to
+// Compressor
+type Brotli int
+
+// Compress implements Compressor
+func (Brotli) Compress(w io.Writer) (io.WriteCloser, error) {
+	return brotli.NewWriter(w), nil
+}
+
+// Decompress implements Compressor
+func (Brotli) Decompress(r io.Reader) (io.ReadCloser, error) {
+	return ioutil.NopCloser(brotli.NewReader(r)), nil
+}
@@ -0,0 +1,372 @@
+package encoding
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Compressor represents an HTTP content-coding, such as gzip, that can be used
+// to transparently compress and decompress the bodies of Requests and
+// Responses.
+type Compressor interface {
+	// Compress should wrap the given io.Writer so that everything written to
+	// the returned io.WriteCloser is compressed.  Close will be called once
+	// everything has been written.
+	Compress(w io.Writer) (io.WriteCloser, error)
+	// Decompress should wrap the given io.Reader so that everything read from
+	// the returned io.ReadCloser has been decompressed.
+	Decompress(r io.Reader) (io.ReadCloser, error)
+}
+
+// CompressionThreshold represents the minimum size, in bytes, that an encoded
+// body must reach before it will be compressed.  Anything smaller is sent
+// as-is, as compressing it would likely not be worth it.
+var CompressionThreshold = 1024
+
+// RequestCompression specifies the content-coding that clients will use to
+// compress Request bodies that exceed the CompressionThreshold.  Since the
+// server may not support the given content-coding, this is empty, and
+// disabled, by default.
+var RequestCompression = ""
+
+// RegisterCompression will register the given Compressor with the given
+// content-coding name within the DefaultRegistry.
+func RegisterCompression(name string, c Compressor) error {
+	return DefaultRegistry.RegisterCompression(name, c)
+}
+
+// GetCompression will retrieve the Compressor registered with the given
+// content-coding name within the DefaultRegistry.
+func GetCompression(name string) (Compressor, error) {
+	return DefaultRegistry.GetCompression(name)
+}
+
+// DecompressBody will wrap the given body with the Decompressors, registered
+// within the DefaultRegistry, specified by the given Content-Encoding header.
+func DecompressBody(contentEncoding string, body io.ReadCloser) (io.ReadCloser, error) {
+	return decompressBody(DefaultRegistry, contentEncoding, body)
+}
+
+// gzip;q=0.8,br,*;q=0
+type acceptEncodingHeader struct {
+	coding []string
+	value  []float32
+}
+
+func parseAcceptEncoding(acceptEncoding string) acceptEncodingHeader {
+	var codings []string
+	var values []float32
+
+	for _, p := range strings.Split(acceptEncoding, ",") {
+		pieces := strings.Split(p, ";")
+		coding := strings.ToLower(strings.TrimSpace(pieces[0]))
+		if coding == "" {
+			continue
+		}
+
+		var value float32 = 1.0
+		for _, param := range pieces[1:] {
+			param = strings.TrimSpace(param)
+			if !strings.HasPrefix(param, "q=") {
+				continue
+			}
+
+			v, err := strconv.ParseFloat(strings.TrimPrefix(param, "q="), 32)
+			if err != nil {
+				continue
+			}
+			value = float32(v)
+		}
+
+		codings = append(codings, coding)
+		values = append(values, value)
+	}
+
+	return acceptEncodingHeader{
+		coding: codings,
+		value:  values,
+	}
+}
+
+// preferred returns the registered content-coding with the highest score.
+// Ties are given to the content-coding that was listed first.  An empty
+// string is returned if no registered content-coding is acceptable.
+func (a acceptEncodingHeader) preferred(reg *Registry) string {
+	var score float32
+	var max = ""
+	var wildcard float32 = -1
+	var listed = map[string]bool{}
+
+	for i, c := range a.coding {
+		listed[c] = true
+		if c == "*" {
+			wildcard = a.value[i]
+			continue
+		}
+
+		if _, err := reg.GetCompression(c); err != nil {
+			continue
+		}
+
+		if a.value[i] > score {
+			score = a.value[i]
+			max = c
+		}
+	}
+
+	if wildcard > score {
+		for _, c := range reg.ListCompressions() {
+			if !listed[c] {
+				return c
+			}
+		}
+	}
+
+	return max
+}
+
+// compressResponseWriter buffers everything written to it until the
+// CompressionThreshold has been reached.  At that point, the Response is
+// committed as compressed.  If the threshold is never reached, the buffered
+// body is written uncompressed on Close.
+type compressResponseWriter struct {
+	http.ResponseWriter
+	name       string
+	compressor Compressor
+	buf        bytes.Buffer
+	cw         io.WriteCloser
+	status     int
+}
+
+// WriteHeader implements net/http.ResponseWriter.  The status is held until
+// we know whether or not the body will be compressed.
+func (crw *compressResponseWriter) WriteHeader(status int) {
+	crw.status = status
+}
+
+// Write implements io.Writer
+func (crw *compressResponseWriter) Write(p []byte) (int, error) {
+	if crw.cw != nil {
+		return crw.cw.Write(p)
+	}
+
+	n, _ := crw.buf.Write(p)
+	if crw.buf.Len() < CompressionThreshold {
+		return n, nil
+	}
+
+	crw.ResponseWriter.Header().Set("Content-Encoding", crw.name)
+	crw.ResponseWriter.Header().Del("Content-Length")
+	crw.writeHeader()
+
+	cw, err := crw.compressor.Compress(crw.ResponseWriter)
+	if err != nil {
+		return 0, err
+	}
+	crw.cw = cw
+
+	if _, err := crw.cw.Write(crw.buf.Bytes()); err != nil {
+		return 0, err
+	}
+	crw.buf.Reset()
+	return n, nil
+}
+
+func (crw *compressResponseWriter) writeHeader() {
+	if crw.status != 0 {
+		crw.ResponseWriter.WriteHeader(crw.status)
+	}
+}
+
+// Close will flush the remaining contents to the underlying ResponseWriter.
+func (crw *compressResponseWriter) Close() error {
+	if crw.cw != nil {
+		return crw.cw.Close()
+	}
+
+	crw.writeHeader()
+	_, err := crw.ResponseWriter.Write(crw.buf.Bytes())
+	return err
+}
+
+type nopCloseResponseWriter struct {
+	http.ResponseWriter
+}
+
+func (nopCloseResponseWriter) Close() error {
+	return nil
+}
+
+// compressResponse will wrap the given ResponseWriter with one that will
+// compress the Response based on the Accept-Encoding stored within the
+// context.
+func compressResponse(ctx context.Context, reg *Registry, w http.ResponseWriter) interface {
+	http.ResponseWriter
+	io.Closer
+} {
+	acceptEncoding, ok := ctx.Value(contextKeyAcceptEncoding).(string)
+	if !ok {
+		// PopulateRequestContext has not been used, so we have no idea of
+		// what the client is capable of.
+		return nopCloseResponseWriter{w}
+	}
+
+	w.Header().Add("Vary", "Accept-Encoding")
+
+	name := parseAcceptEncoding(acceptEncoding).preferred(reg)
+	if name == "" {
+		return nopCloseResponseWriter{w}
+	}
+
+	c, err := reg.GetCompression(name)
+	if err != nil {
+		return nopCloseResponseWriter{w}
+	}
+
+	return &compressResponseWriter{
+		ResponseWriter: w,
+		name:           name,
+		compressor:     c,
+	}
+}
+
+type multiReadCloser struct {
+	io.Reader
+	closers []io.Closer
+}
+
+func (mrc multiReadCloser) Close() error {
+	var err error
+	for i := len(mrc.closers) - 1; i >= 0; i-- {
+		if e := mrc.closers[i].Close(); e != nil && err == nil {
+			err = e
+		}
+	}
+	return err
+}
+
+// decompressBody will wrap the given body with the Decompressors specified by
+// the given Content-Encoding header.
+func decompressBody(reg *Registry, contentEncoding string, body io.ReadCloser) (io.ReadCloser, error) {
+	codings := strings.Split(contentEncoding, ",")
+	mrc := multiReadCloser{
+		Reader:  body,
+		closers: []io.Closer{body},
+	}
+
+	// the codings are listed in the order they were applied, so they need to
+	// be removed in the reverse order.
+	for i := len(codings) - 1; i >= 0; i-- {
+		coding := strings.ToLower(strings.TrimSpace(codings[i]))
+		if coding == "" || coding == "identity" {
+			continue
+		}
+
+		c, err := reg.GetCompression(coding)
+		if err != nil {
+			return nil, err
+		}
+
+		rc, err := c.Decompress(mrc.Reader)
+		if err != nil {
+			return nil, err
+		}
+
+		mrc.Reader = rc
+		mrc.closers = append(mrc.closers, rc)
+	}
+
+	return mrc, nil
+}
+
+// decompressRequest will transparently decompress the body of the Request,
+// if a Content-Encoding has been specified.
+func decompressRequest(reg *Registry, r *http.Request) error {
+	contentEncoding := r.Header.Get("Content-Encoding")
+	if contentEncoding == "" || r.Body == nil {
+		return nil
+	}
+
+	body, err := decompressBody(reg, contentEncoding, r.Body)
+	if err != nil {
+		return err
+	}
+
+	r.Body = body
+	r.Header.Del("Content-Encoding")
+	r.ContentLength = -1
+	return nil
+}
+
+// decompressResponse will transparently decompress the body of the Response,
+// if a Content-Encoding has been specified.
+func decompressResponse(reg *Registry, r *http.Response) error {
+	contentEncoding := r.Header.Get("Content-Encoding")
+	if contentEncoding == "" || r.Body == nil {
+		return nil
+	}
+
+	body, err := decompressBody(reg, contentEncoding, r.Body)
+	if err != nil {
+		return err
+	}
+
+	r.Body = body
+	r.Header.Del("Content-Encoding")
+	r.ContentLength = -1
+	return nil
+}
+
+// compressRequest will compress the already encoded body of the Request with
+// the RequestCompression, provided it exceeds the CompressionThreshold.
+func compressRequest(reg *Registry, r *http.Request) error {
+	if RequestCompression == "" || r.Body == nil {
+		return nil
+	}
+
+	c, err := reg.GetCompression(RequestCompression)
+	if err != nil {
+		return err
+	}
+
+	byts, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return err
+	}
+
+	if len(byts) < CompressionThreshold {
+		r.Body = ioutil.NopCloser(bytes.NewReader(byts))
+		r.ContentLength = int64(len(byts))
+		return nil
+	}
+
+	var buf bytes.Buffer
+	cw, err := c.Compress(&buf)
+	if err != nil {
+		return err
+	}
+
+	if _, err := cw.Write(byts); err != nil {
+		return err
+	}
+
+	if err := cw.Close(); err != nil {
+		return err
+	}
+
+	r.Header.Set("Content-Encoding", RequestCompression)
+	r.Body = ioutil.NopCloser(&buf)
+	r.ContentLength = int64(buf.Len())
+	return nil
+}
+
+// acceptEncoding returns the value of the Accept-Encoding header that clients
+// will send, listing every registered content-coding.
+func acceptEncoding(reg *Registry) string {
+	return strings.Join(reg.ListCompressions(), ", ")
+}
@@ -0,0 +1,195 @@
+package encoding_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/ayiga/go-kit-middlewarer/encoding"
+)
+
+func compressionContext(acceptEncoding string) context.Context {
+	r, err := http.NewRequest("GET", "/not/important", nil)
+	if err != nil {
+		panic(err)
+	}
+	r.Header.Set("Accept-Encoding", acceptEncoding)
+	return encoding.PopulateRequestContext(context.Background(), r)
+}
+
+func TestEncodeResponseCompressedAboveThreshold(t *testing.T) {
+	ctx := compressionContext("gzip;q=0.5, br;q=0.1")
+	req := &request{
+		Str: strings.Repeat("a", encoding.CompressionThreshold),
+	}
+	req.embedMime = new(embedMime)
+	req.SetMime("application/json")
+
+	buf := new(bytes.Buffer)
+	rw := createResponseWriter(buf)
+	if err := encoding.Default().EncodeResponse()(ctx, rw, req); err != nil {
+		t.Fatalf("Unable to Encode Response: %s", err)
+	}
+
+	if got, want := rw.Header().Get("Content-Encoding"), "gzip"; got != want {
+		t.Fatalf("Content-Encoding:\ngot:\n\t%s\nwant:\n\t%s", got, want)
+	}
+
+	if got, want := rw.Header().Get("Vary"), "Accept-Encoding"; got != want {
+		t.Errorf("Vary:\ngot:\n\t%s\nwant:\n\t%s", got, want)
+	}
+
+	ro := new(http.Response)
+	ro.StatusCode = 200
+	ro.Body = ioutil.NopCloser(buf)
+	ro.Header = rw.Header()
+
+	resp := new(request)
+	resp.embedMime = new(embedMime)
+	if _, err := encoding.Default().DecodeResponse(resp)(ctx, ro); err != nil {
+		t.Fatalf("Unable to Decode Response: %s", err)
+	}
+
+	if got, want := resp.Str, req.Str; got != want {
+		t.Errorf("resp.Str:\ngot:\n\t%s\nwant:\n\t%s", got, want)
+	}
+}
+
+func TestEncodeResponseUncompressedBelowThreshold(t *testing.T) {
+	ctx := compressionContext("gzip")
+	req := &request{
+		Str: "foo",
+	}
+	req.embedMime = new(embedMime)
+	req.SetMime("application/json")
+
+	buf := new(bytes.Buffer)
+	rw := createResponseWriter(buf)
+	if err := encoding.Default().EncodeResponse()(ctx, rw, req); err != nil {
+		t.Fatalf("Unable to Encode Response: %s", err)
+	}
+
+	if got, want := rw.Header().Get("Content-Encoding"), ""; got != want {
+		t.Errorf("Content-Encoding:\ngot:\n\t%s\nwant:\n\t%s", got, want)
+	}
+
+	str := "{\"str\":\"foo\",\"num\":0,\"bool\":false,\"null\":null}\n"
+	if got, want := buf.String(), str; got != want {
+		t.Errorf("Body:\ngot:\n\t%s\nwant:\n\t%s", got, want)
+	}
+}
+
+func TestEncodeResponseIdentityOnly(t *testing.T) {
+	ctx := compressionContext("identity, *;q=0")
+	req := &request{
+		Str: strings.Repeat("a", encoding.CompressionThreshold),
+	}
+	req.embedMime = new(embedMime)
+	req.SetMime("application/json")
+
+	buf := new(bytes.Buffer)
+	rw := createResponseWriter(buf)
+	if err := encoding.Default().EncodeResponse()(ctx, rw, req); err != nil {
+		t.Fatalf("Unable to Encode Response: %s", err)
+	}
+
+	if got, want := rw.Header().Get("Content-Encoding"), ""; got != want {
+		t.Errorf("Content-Encoding:\ngot:\n\t%s\nwant:\n\t%s", got, want)
+	}
+}
+
+func TestDecodeRequestCompressed(t *testing.T) {
+	for _, coding := range []string{"gzip", "br", "zstd"} {
+		c, err := encoding.GetCompression(coding)
+		if err != nil {
+			t.Fatalf("Unable to get Compressor %s: %s", coding, err)
+		}
+
+		buf := new(bytes.Buffer)
+		cw, err := c.Compress(buf)
+		if err != nil {
+			t.Fatalf("Unable to create Compressor %s: %s", coding, err)
+		}
+		cw.Write([]byte("{\"str\":\"bar\",\"num\":10,\"bool\":true,\"null\":null}"))
+		cw.Close()
+
+		r, err := http.NewRequest("POST", "/not/important", buf)
+		if err != nil {
+			panic(err)
+		}
+		r.Header.Set("Content-Type", "application/json")
+		r.Header.Set("Content-Encoding", coding)
+
+		req := new(request)
+		req.embedMime = new(embedMime)
+		if _, err := encoding.Default().DecodeRequest(req)(context.Background(), r); err != nil {
+			t.Fatalf("Unable to Decode %s Request: %s", coding, err)
+		}
+
+		if got, want := req.Str, "bar"; got != want {
+			t.Errorf("%s req.Str:\ngot:\n\t%s\nwant:\n\t%s", coding, got, want)
+		}
+	}
+}
+
+func TestDecodeRequestUnsupportedContentEncoding(t *testing.T) {
+	r, err := http.NewRequest("POST", "/not/important", bytes.NewBufferString("{}"))
+	if err != nil {
+		panic(err)
+	}
+	r.Header.Set("Content-Type", "application/json")
+	r.Header.Set("Content-Encoding", "compress")
+
+	req := new(request)
+	req.embedMime = new(embedMime)
+	_, err = encoding.Default().DecodeRequest(req)(context.Background(), r)
+	if got, want := err, encoding.ErrUnsupportedContentEncoding; got != want {
+		t.Errorf("err:\ngot:\n\t%v\nwant:\n\t%v", got, want)
+	}
+}
+
+func TestEncodeRequestAcceptEncoding(t *testing.T) {
+	req := &request{
+		Str: strings.Repeat("a", encoding.CompressionThreshold),
+	}
+	req.embedMime = new(embedMime)
+	req.SetMime("application/json")
+
+	encoding.RequestCompression = "gzip"
+	defer func() { encoding.RequestCompression = "" }()
+
+	r, err := http.NewRequest("POST", "/not/important", nil)
+	if err != nil {
+		panic(err)
+	}
+
+	if err := encoding.Default().EncodeRequest()(context.Background(), r, req); err != nil {
+		t.Fatalf("Unable to Encode Request: %s", err)
+	}
+
+	if r.Header.Get("Accept-Encoding") == "" {
+		t.Errorf("Accept-Encoding was not set")
+	}
+
+	if got, want := r.Header.Get("Content-Encoding"), "gzip"; got != want {
+		t.Fatalf("Content-Encoding:\ngot:\n\t%s\nwant:\n\t%s", got, want)
+	}
+
+	gr, err := gzip.NewReader(r.Body)
+	if err != nil {
+		t.Fatalf("Unable to read compressed Request: %s", err)
+	}
+
+	byts, err := ioutil.ReadAll(gr)
+	if err != nil {
+		t.Fatalf("Unable to read compressed Request: %s", err)
+	}
+
+	if !strings.Contains(string(byts), req.Str) {
+		t.Errorf("Decompressed Request does not contain the encoded Request: %s", byts)
+	}
+}
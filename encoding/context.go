@@ -0,0 +1,23 @@
+package encoding
+
+import (
+	"context"
+	"net/http"
+)
+
+type contextKey int
+
+const (
+	contextKeyAcceptEncoding contextKey = iota
+	contextKeyMimePreference
+	contextKeyLimits
+	contextKeyAccept
+)
+
+// PopulateRequestContext is a github.com/go-kit/kit/transport/http.RequestFunc
+// that stores the negotiation headers of the incoming Request within the
+// context, so that they are available when encoding the Response.
+func PopulateRequestContext(ctx context.Context, r *http.Request) context.Context {
+	ctx = context.WithValue(ctx, contextKeyAccept, r.Header.Get("Accept"))
+	return context.WithValue(ctx, contextKeyAcceptEncoding, r.Header.Get("Accept-Encoding"))
+}
@@ -23,9 +23,35 @@ type GenerateDecoder func(io.Reader) Decoder
 // MakeRequestDecoder exists to help bridge the gaps for encoding.  It takes a
 // request interface type, and a GenerateDecoder, and ultimately returns a
 // function that can decode the given request.
+//
+// The Limits specified within the context, via SetLimits, or the
+// DefaultLimits are enforced while decoding.
 func MakeRequestDecoder(request interface{}, gen GenerateDecoder) httptransport.DecodeRequestFunc {
 	return func(ctx context.Context, r *http.Request) (interface{}, error) {
-		if err := gen(r.Body).Decode(request); err != nil {
+		limits := limitsFromContext(ctx)
+		body := r.Body
+		var lb *limitedBody
+		if limits.MaxBodySize > 0 {
+			if r.ContentLength > limits.MaxBodySize {
+				return nil, ErrRequestEntityTooLarge
+			}
+
+			lb = limitBody(r.Body, limits.MaxBodySize)
+			body = lb
+		}
+
+		dec := gen(body)
+		if d, ok := dec.(interface {
+			DisallowUnknownFields()
+		}); ok && limits.DisallowUnknownFields {
+			d.DisallowUnknownFields()
+		}
+
+		if err := dec.Decode(request); err != nil {
+			if lb != nil && lb.exceeded {
+				// the Decoder may have wrapped, or replaced, our error.
+				return nil, ErrRequestEntityTooLarge
+			}
 			return nil, err
 		}
 		return request, nil
@@ -35,6 +61,13 @@ func MakeRequestDecoder(request interface{}, gen GenerateDecoder) httptransport.
 // MakeResponseDecoder exists to help bridge the gaps for encoding.  It takes a
 // response interface type, and a GenerateDecoder, and ultimately returns a
 // function that can decode a given Response.
+//
+// Responses with a non 2xx status code are decoded as a WrapperError, or as
+// Problem Details if that is their Content-Type.  If the wrapped error's type
+// has been registered with RegisterError, and it wraps no other errors than
+// those it unwraps to by itself, the typed error is returned, otherwise the
+// WrapperError is returned, reporting the status code via its StatusCode
+// method.
 func MakeResponseDecoder(response interface{}, gen GenerateDecoder) httptransport.DecodeResponseFunc {
 	return func(ctx context.Context, r *http.Response) (interface{}, error) {
 		if r.StatusCode < 200 || r.StatusCode > 299 {
@@ -55,6 +88,10 @@ func MakeResponseDecoder(response interface{}, gen GenerateDecoder) httptranspor
 				return errors.New(string(c)), nil
 			}
 
+			if isProblemMime(ct.contentType) {
+				return decodeProblem(ct.contentType, r.Body, r.StatusCode)
+			}
+
 			var we WrapperError
 			// we'll let the current Decoder try to decode the error.  In this
 			// case we'll give it the custom error type we've created, to wrap
@@ -63,11 +100,14 @@ func MakeResponseDecoder(response interface{}, gen GenerateDecoder) httptranspor
 				return nil, err
 			}
 
-			if _, ok := we.Err.(error); we.Err != nil && ok {
-				return we.Err, nil
-			}
+			// the error is unknown to us, or wraps other errors, so we'll
+			// keep the status it was sent with.
+			we.status = r.StatusCode
 
-			return we, nil
+			// if the error type is registered, and it doesn't wrap anything
+			// it wouldn't unwrap to by itself, we're able to return the same
+			// typed error the server responded with.
+			return we.unwrap(), nil
 		}
 
 		if err := gen(r.Body).Decode(response); err != nil {
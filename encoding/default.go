@@ -6,7 +6,6 @@ import (
 	"io/ioutil"
 	"mime"
 	"net/http"
-	"strconv"
 	"strings"
 
 	httptransport "github.com/go-kit/kit/transport/http"
@@ -25,68 +24,32 @@ type EmbededMime interface {
 	SetMime(mime string)
 }
 
-// Default returns the default RequestResponseEncoding
+// Default returns the default RequestResponseEncoding, which negotiates
+// between the encodings registered within the DefaultRegistry.
 func Default() RequestResponseEncoding {
-	return def(0)
+	return defaultEncoding
 }
 
-// def is the default Encoding handler.  It will attempt to resolve all
-// http transmitted encodings based on the information contained within the
-// HTTP Headers.
-type def int
-
-const DefaultEncoding = "application/json"
+var defaultEncoding = New(DefaultRegistry)
 
-// (\w+\/\w+[;q=score],?)+
-type acceptContentHeader struct {
-	mime  []string
-	value []float32
-}
-
-func parseAccept(accept string) acceptContentHeader {
-	var mimes []string
-	var values []float32
-
-	parts := strings.Split(accept, ",")
-	for _, p := range parts {
-		mediaType, params, err := mime.ParseMediaType(p)
-		if err != nil {
-			continue
-		}
-
-		if params["q"] == "" {
-			mimes = append(mimes, mediaType)
-			values = append(values, 1.0)
-			continue
-		}
-
-		v, err := strconv.ParseFloat(params["q"], 32)
-		if err != nil {
-			continue
-		}
-		mimes = append(mimes, mediaType)
-		values = append(values, float32(v))
-	}
-
-	return acceptContentHeader{
-		mime:  mimes,
-		value: values,
+// New returns a RequestResponseEncoding that behaves like Default, except
+// that it negotiates between the encodings registered within the given
+// Registry.
+func New(reg *Registry) RequestResponseEncoding {
+	return def{
+		registry: reg,
 	}
 }
 
-func (a acceptContentHeader) highest() string {
-	var score float32
-	var max = ""
-	for i, m := range a.mime {
-		if a.value[i] > score {
-			score = a.value[i]
-			max = m
-		}
-	}
-
-	return max
+// def is the default Encoding handler.  It will attempt to resolve all
+// http transmitted encodings based on the information contained within the
+// HTTP Headers.
+type def struct {
+	registry *Registry
 }
 
+const DefaultEncoding = "application/json"
+
 // specific;options,...
 type contentTypeValue struct {
 	contentType string
@@ -104,112 +67,123 @@ func parseContentType(contentType string) contentTypeValue {
 	}
 }
 
-func getFromEmbededMime(em EmbededMime) (mime string, encoding RequestResponseEncoding, err error) {
+func getFromEmbededMime(reg *Registry, em EmbededMime) (mime string, encoding RequestResponseEncoding, err error) {
 	if mime = em.GetMime(); mime != "" {
-		encoding, err = Get(mime)
+		encoding, err = reg.Get(mime)
 		return
 	}
 
 	return "", nil, ErrMimeNotSpecified
 }
 
-func encodeRequest(mime string, ctx context.Context, encoding RequestResponseEncoding, r *http.Request, request interface{}) error {
+func (d def) encodeRequest(mime string, ctx context.Context, encoding RequestResponseEncoding, r *http.Request, request interface{}) error {
 	r.Header.Set("Content-Type", mime)
 	r.Header.Set("Accept", mime)
-	return encoding.EncodeRequest()(ctx, r, request)
-}
+	if r.Header.Get("Accept-Encoding") == "" {
+		r.Header.Set("Accept-Encoding", acceptEncoding(d.registry))
+	}
 
-func encodeResponse(mime string, ctx context.Context, encoding RequestResponseEncoding, w http.ResponseWriter, response interface{}) error {
-	w.Header().Set("Content-Type", mime)
-	return encoding.EncodeResponse()(ctx, w, response)
+	if err := encoding.EncodeRequest()(ctx, r, request); err != nil {
+		return err
+	}
+
+	return compressRequest(d.registry, r)
 }
 
-func transferMimeDetails(em EmbededMime, ct contentTypeValue, accept acceptContentHeader) {
-	if len(accept.mime) > 0 {
-		acpt := accept.highest()
-		if _, err := Get(acpt); err == nil {
-			em.SetMime(acpt)
-			return
-		}
+func (d def) encodeResponse(mime string, ctx context.Context, encoding RequestResponseEncoding, w http.ResponseWriter, response interface{}) error {
+	w.Header().Set("Content-Type", mime)
+	cw := compressResponse(ctx, d.registry, w)
+	if err := encoding.EncodeResponse()(ctx, cw, response); err != nil {
+		return err
 	}
 
-	em.SetMime(ct.contentType)
+	return cw.Close()
 }
 
 // EncodeRequest implements RequestResponseEncoding
-func (def) EncodeRequest() httptransport.EncodeRequestFunc {
+func (d def) EncodeRequest() httptransport.EncodeRequestFunc {
 	return func(ctx context.Context, r *http.Request, request interface{}) error {
 		if em, ok := request.(EmbededMime); ok {
-			if mime, encoding, err := getFromEmbededMime(em); err == nil {
-				return encodeRequest(mime, ctx, encoding, r, request)
+			if mime, encoding, err := getFromEmbededMime(d.registry, em); err == nil {
+				return d.encodeRequest(mime, ctx, encoding, r, request)
 			}
 		}
 
 		// we failed, unfortunately.  However, we are making a request
 		// so we can just specify the default encoding
-		encoding, err := Get(DefaultEncoding)
+		encoding, err := d.registry.Get(DefaultEncoding)
 		if err != nil {
 			// we have really big problems at this point
 			return err
 		}
 
-		return encodeRequest(DefaultEncoding, ctx, encoding, r, request)
+		return d.encodeRequest(DefaultEncoding, ctx, encoding, r, request)
 	}
 }
 
 // DecodeRequest implements RequestResponseEncoding
-func (def) DecodeRequest(request interface{}) httptransport.DecodeRequestFunc {
+func (d def) DecodeRequest(request interface{}) httptransport.DecodeRequestFunc {
 	return func(ctx context.Context, r *http.Request) (interface{}, error) {
+		if err := decompressRequest(d.registry, r); err != nil {
+			return nil, err
+		}
+
 		ct := parseContentType(r.Header.Get("Content-Type"))
 		accept := parseAccept(r.Header.Get("Accept"))
 
 		if ct.contentType == "" {
 			// let's try to guess the type based on the request
-			return hintResolver(0).DecodeRequest(request)(ctx, r)
-		} else if encoding, err := Get(ct.contentType); err == nil {
+			return hintResolver{d.registry}.DecodeRequest(request)(ctx, r)
+		} else if encoding, err := d.registry.Get(ct.contentType); err == nil {
 			if em, ok := request.(EmbededMime); ok {
-				transferMimeDetails(em, ct, accept)
+				if err := transferMimeDetails(ctx, d.registry, em, ct, accept); err != nil {
+					return nil, err
+				}
 			}
 
 			return encoding.DecodeRequest(request)(ctx, r)
 		}
 
 		// let's try to guess the type based on the request
-		return hintResolver(0).DecodeRequest(request)(ctx, r)
+		return hintResolver{d.registry}.DecodeRequest(request)(ctx, r)
 	}
 }
 
 // EncodeResponse implements RequestResponseEncoding
-func (def) EncodeResponse() httptransport.EncodeResponseFunc {
+func (d def) EncodeResponse() httptransport.EncodeResponseFunc {
 	return func(ctx context.Context, w http.ResponseWriter, response interface{}) error {
 		if em, ok := response.(EmbededMime); ok {
-			if mime, encoding, err := getFromEmbededMime(em); err == nil {
-				return encodeResponse(mime, ctx, encoding, w, response)
+			if mime, encoding, err := getFromEmbededMime(d.registry, em); err == nil {
+				return d.encodeResponse(mime, ctx, encoding, w, response)
 			}
 		}
 
 		// we failed, but we'll try to use our default, so that we will
 		// at least make some forward progress
 
-		encoding, err := Get(DefaultEncoding)
+		encoding, err := d.registry.Get(DefaultEncoding)
 		if err != nil {
 			return err
 
 		}
 
-		return encodeResponse(DefaultEncoding, ctx, encoding, w, response)
+		return d.encodeResponse(DefaultEncoding, ctx, encoding, w, response)
 	}
 }
 
 // DecodeResponse implements RequestResponseEncoding
-func (def) DecodeResponse(response interface{}) httptransport.DecodeResponseFunc {
+func (d def) DecodeResponse(response interface{}) httptransport.DecodeResponseFunc {
 	return func(ctx context.Context, r *http.Response) (interface{}, error) {
+		if err := decompressResponse(d.registry, r); err != nil {
+			return nil, err
+		}
+
 		ct := parseContentType(r.Header.Get("Content-Type"))
 		if ct.contentType == "" {
 			// fall back
 			// let's try to guess the type based on the response
-			return hintResolver(0).DecodeResponse(response)(ctx, r)
-		} else if encoding, err := Get(ct.contentType); err == nil {
+			return hintResolver{d.registry}.DecodeResponse(response)(ctx, r)
+		} else if encoding, err := d.registry.Get(ct.contentType); err == nil {
 			return encoding.DecodeResponse(response)(ctx, r)
 		} else if r.StatusCode < 200 || r.StatusCode > 299 {
 			if ct.contentType == "text/plain" {
@@ -227,10 +201,10 @@ func (def) DecodeResponse(response interface{}) httptransport.DecodeResponseFunc
 			}
 
 			var we WrapperError
-			return hintResolver(0).DecodeResponse(&we)(ctx, r)
+			return hintResolver{d.registry}.DecodeResponse(&we)(ctx, r)
 		}
 
 		// let's try to guess the type based on the response
-		return hintResolver(0).DecodeResponse(response)(ctx, r)
+		return hintResolver{d.registry}.DecodeResponse(response)(ctx, r)
 	}
 }
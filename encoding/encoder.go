@@ -50,9 +50,59 @@ func MakeResponseEncoder(gen GenerateEncoder) httptransport.EncodeResponseFunc {
 }
 
 // MakeErrorEncoder will take a generic GenerateEncoder function and will
-// return an ErrorEncoder
+// return an ErrorEncoder.
+//
+// The status code is determined by ErrorStatus.  If the error implements
+// github.com/go-kit/kit/transport/http.Headerer, its headers will also be
+// added to the response.
+//
+// If the client explicitly Accepts application/problem+json, or
+// application/problem+xml, the error will be encoded as Problem Details
+// instead.  The Accept header is only known if PopulateRequestContext has been
+// run before the Endpoint.
 func MakeErrorEncoder(gen RequestResponseEncoding) httptransport.ErrorEncoder {
 	return func(ctx context.Context, err error, w http.ResponseWriter) {
-		gen.EncodeResponse()(ctx, w, err)
+		errorHeaders(err, w.Header())
+		srw := &statusResponseWriter{
+			ResponseWriter: w,
+			status:         ErrorStatus(err),
+		}
+
+		if mime := problemMime(ctx); mime != "" {
+			encodeProblem(srw, mime, err, srw.status)
+			srw.flush()
+			return
+		}
+
+		gen.EncodeResponse()(ctx, srw, err)
+		srw.flush()
+	}
+}
+
+// statusResponseWriter delays writing the status until the body is first
+// written, so that the headers may still be modified while encoding.
+type statusResponseWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+// WriteHeader implements net/http.ResponseWriter
+func (srw *statusResponseWriter) WriteHeader(status int) {
+	if !srw.wroteHeader {
+		srw.status = status
+	}
+}
+
+// Write implements io.Writer
+func (srw *statusResponseWriter) Write(p []byte) (int, error) {
+	srw.flush()
+	return srw.ResponseWriter.Write(p)
+}
+
+func (srw *statusResponseWriter) flush() {
+	if !srw.wroteHeader {
+		srw.wroteHeader = true
+		srw.ResponseWriter.WriteHeader(srw.status)
 	}
 }
@@ -0,0 +1,128 @@
+package encoding
+
+import (
+	"reflect"
+	"strings"
+)
+
+var registeredSentinels = make(map[string]error)
+
+// RegisterSentinel will register the given error value, such as one returned
+// by errors.New, with the given code.  When the error is transmitted, the code
+// is transmitted with it, and the very same error value is returned when it is
+// decoded, so that comparisons, and errors.Is, will work across the wire.
+//
+// Like RegisterError, this is expected to be called during initialization.
+func RegisterSentinel(code string, e error) error {
+	if e == nil || !reflect.TypeOf(e).Comparable() {
+		// we would be unable to identify the error when wrapping it
+		return ErrBlacklisted
+	}
+
+	if registeredSentinels[code] != nil || sentinelCode(e) != "" {
+		return ErrDuplicate
+	}
+
+	registeredSentinels[code] = e
+	return nil
+}
+
+// sentinelCode returns the code the given error was registered with via
+// RegisterSentinel, if any.
+func sentinelCode(e error) string {
+	if !reflect.TypeOf(e).Comparable() {
+		return ""
+	}
+
+	for code, sentinel := range registeredSentinels {
+		if sentinel == e {
+			return code
+		}
+	}
+
+	return ""
+}
+
+// unwrapErrors returns the errors wrapped by the given error, supporting both
+// Unwrap() error, and the Unwrap() []error returned by errors.Join.
+func unwrapErrors(e error) []error {
+	switch u := e.(type) {
+	case interface{ Unwrap() error }:
+		if w := u.Unwrap(); w != nil {
+			return []error{w}
+		}
+	case interface{ Unwrap() []error }:
+		var errs []error
+		for _, w := range u.Unwrap() {
+			if w != nil {
+				errs = append(errs, w)
+			}
+		}
+		return errs
+	}
+
+	return nil
+}
+
+// Unwrap returns the decoded error, if its type or value has been registered,
+// followed by the errors it wrapped.  This allows errors.Is, and errors.As, to
+// inspect the whole chain of errors that was transmitted.
+func (we WrapperError) Unwrap() []error {
+	var errs []error
+	if e, ok := we.known(); ok {
+		errs = append(errs, e)
+	}
+
+	for _, w := range we.Wrapped {
+		errs = append(errs, w.unwrap())
+	}
+
+	return errs
+}
+
+// unwrap returns the most specific representation of the WrapperError.  If the
+// error is known, and it wrapped nothing, or wraps the very same errors by
+// itself, such as a registered type that unwraps to a registered sentinel, the
+// error itself is returned.  Otherwise, the WrapperError is returned.
+func (we WrapperError) unwrap() error {
+	if e, ok := we.known(); ok && we.reproducedBy(e) {
+		return e
+	}
+
+	return we
+}
+
+// reproducedBy reports whether the errors the given error unwraps to are those
+// the WrapperError was transmitted wrapping, so that nothing is lost by
+// returning it in place of the WrapperError.
+func (we WrapperError) reproducedBy(e error) bool {
+	wrapped := unwrapErrors(e)
+	if len(wrapped) != len(we.Wrapped) {
+		return false
+	}
+
+	for i, w := range we.Wrapped {
+		if !reflect.DeepEqual(w.unwrap(), wrapped[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// known returns the decoded error, if its type has been registered with
+// RegisterError, or the sentinel, if its code has been registered with
+// RegisterSentinel.
+func (we WrapperError) known() (error, bool) {
+	if e, ok := we.Err.(error); ok {
+		return e, true
+	}
+
+	e, ok := registeredSentinels[we.Code]
+	return e, ok
+}
+
+// tagName strips any options, such as omitempty, from a struct tag.
+func tagName(tag string) string {
+	return strings.SplitN(tag, ",", 2)[0]
+}
@@ -0,0 +1,196 @@
+package encoding_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/ayiga/go-kit-middlewarer/encoding"
+)
+
+var ErrChainSentinel = errors.New("chain sentinel")
+
+func init() {
+	encoding.RegisterSentinel("encoding_test.ErrChainSentinel", ErrChainSentinel)
+}
+
+func roundTripError(t *testing.T, enc encoding.RequestResponseEncoding, mime string, e error) interface{} {
+	ctx := context.Background()
+	buf := new(bytes.Buffer)
+	rw := createResponseWriter(buf)
+	rw.WriteHeader(500)
+	if err := enc.EncodeResponse()(ctx, rw, e); err != nil {
+		t.Fatalf("Unable to Encode Response: %s", err)
+	}
+
+	ro := new(http.Response)
+	ro.StatusCode = rw.statusCode
+	ro.Body = ioutil.NopCloser(buf)
+	ro.Header = make(http.Header)
+	ro.Header.Set("Content-Type", mime)
+
+	r, err := encoding.Default().DecodeResponse(new(request))(ctx, ro)
+	if err != nil {
+		t.Fatalf("Unable to Decode Response: %s", err)
+	}
+
+	return r
+}
+
+func TestRegisterSentinelDuplicate(t *testing.T) {
+	if got, want := encoding.RegisterSentinel("another.code", ErrChainSentinel), encoding.ErrDuplicate; got != want {
+		t.Errorf("RegisterSentinel:\ngot:\n\t%v\nwant:\n\t%v", got, want)
+	}
+}
+
+func TestErrorChainRoundTrip(t *testing.T) {
+	encodings := []struct {
+		enc  encoding.RequestResponseEncoding
+		mime string
+	}{
+		{encoding.JSON(0), "application/json"},
+		{encoding.XML(0), "application/xml"},
+	}
+
+	for _, e := range encodings {
+		sent := fmt.Errorf("lookup failed: %w", errors.Join(
+			ErrChainSentinel,
+			fmt.Errorf("resource: %w", NotFoundError{Resource: "foo"}),
+			errors.New("unknown"),
+		))
+
+		r := roundTripError(t, e.enc, e.mime, sent)
+		err, ok := r.(error)
+		if !ok {
+			t.Fatalf("%s: Unable to cast returned response into an error", e.mime)
+		}
+
+		if got, want := err.Error(), sent.Error(); got != want {
+			t.Errorf("%s: .Error():\ngot:\n\t%s\nwant:\n\t%s", e.mime, got, want)
+		}
+
+		if !errors.Is(err, ErrChainSentinel) {
+			t.Errorf("%s: errors.Is(err, ErrChainSentinel) = false", e.mime)
+		}
+
+		var nf NotFoundError
+		if !errors.As(err, &nf) {
+			t.Errorf("%s: errors.As(err, *NotFoundError) = false", e.mime)
+		} else if got, want := nf, (NotFoundError{Resource: "foo"}); !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: NotFoundError:\ngot:\n\t%#v\nwant:\n\t%#v", e.mime, got, want)
+		}
+
+		// unknown errors keep their message.
+		we := err.(encoding.WrapperError)
+		if got, want := we.Wrapped[0].Wrapped[2].Error(), "unknown"; got != want {
+			t.Errorf("%s: unknown .Error():\ngot:\n\t%s\nwant:\n\t%s", e.mime, got, want)
+		}
+	}
+}
+
+// ChainedSentinelError unwraps to a registered sentinel by itself.
+type ChainedSentinelError struct {
+	Resource string `json:"resource" xml:"resource"`
+}
+
+func (e ChainedSentinelError) Error() string {
+	return e.Resource + ": " + ErrChainSentinel.Error()
+}
+
+func (e ChainedSentinelError) Unwrap() error {
+	return ErrChainSentinel
+}
+
+func init() {
+	encoding.RegisterError(ChainedSentinelError{})
+}
+
+func TestErrorUnwrappingToSentinelRoundTrip(t *testing.T) {
+	encodings := []struct {
+		enc  encoding.RequestResponseEncoding
+		mime string
+	}{
+		{encoding.JSON(0), "application/json"},
+		{encoding.XML(0), "application/xml"},
+	}
+
+	for _, e := range encodings {
+		sent := ChainedSentinelError{Resource: "foo"}
+
+		// the error wraps nothing that it doesn't unwrap to by itself, so it
+		// is returned rather than a WrapperError.
+		r := roundTripError(t, e.enc, e.mime, sent)
+		if got, want := r, interface{}(sent); got != want {
+			t.Errorf("%s: Error:\ngot:\n\t%#v\nwant:\n\t%#v", e.mime, got, want)
+		}
+
+		// while wrapping it within another error keeps the WrapperError.
+		r = roundTripError(t, e.enc, e.mime, fmt.Errorf("lookup: %w", sent))
+		if _, ok := r.(encoding.WrapperError); !ok {
+			t.Errorf("%s: Expected a WrapperError, got: %#v", e.mime, r)
+		}
+
+		if err, _ := r.(error); !errors.Is(err, ErrChainSentinel) {
+			t.Errorf("%s: errors.Is(err, ErrChainSentinel) = false", e.mime)
+		}
+	}
+}
+
+func TestSentinelRoundTrip(t *testing.T) {
+	r := roundTripError(t, encoding.JSON(0), "application/json", ErrChainSentinel)
+	if got, want := r, interface{}(ErrChainSentinel); got != want {
+		t.Errorf("Error:\ngot:\n\t%#v\nwant:\n\t%#v", got, want)
+	}
+}
+
+var ErrSentinelNotFound = errors.New("sentinel not found")
+
+func init() {
+	encoding.RegisterSentinel("encoding_test.ErrSentinelNotFound", ErrSentinelNotFound)
+	encoding.RegisterErrorStatus(ErrSentinelNotFound, http.StatusNotFound)
+}
+
+func TestSentinelErrorStatus(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{ErrSentinelNotFound, http.StatusNotFound},
+		{fmt.Errorf("lookup: %w", ErrSentinelNotFound), http.StatusNotFound},
+		{errors.New("sentinel not found"), http.StatusInternalServerError},
+		{ErrChainSentinel, http.StatusInternalServerError},
+	}
+
+	for _, test := range tests {
+		if got := encoding.ErrorStatus(test.err); got != test.want {
+			t.Errorf("ErrorStatus(%v):\ngot:\n\t%d\nwant:\n\t%d", test.err, got, test.want)
+		}
+	}
+}
+
+func TestSentinelProblemRoundTrip(t *testing.T) {
+	for _, mime := range []string{encoding.ProblemJSONMime, encoding.ProblemXMLMime} {
+		ctx := problemContext(mime)
+		rec := httptest.NewRecorder()
+		encoding.MakeErrorEncoder(encoding.Default())(ctx, ErrSentinelNotFound, rec)
+
+		if got, want := rec.Code, http.StatusNotFound; got != want {
+			t.Errorf("%s: Status:\ngot:\n\t%d\nwant:\n\t%d", mime, got, want)
+		}
+
+		r, err := encoding.Default().DecodeResponse(new(request))(ctx, rec.Result())
+		if err != nil {
+			t.Fatalf("%s: Unable to Decode Response: %s", mime, err)
+		}
+
+		if got, want := r, interface{}(ErrSentinelNotFound); got != want {
+			t.Errorf("%s: Error:\ngot:\n\t%#v\nwant:\n\t%#v", mime, got, want)
+		}
+	}
+}
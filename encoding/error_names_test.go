@@ -0,0 +1,83 @@
+package encoding_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ayiga/go-kit-middlewarer/encoding"
+)
+
+type RenamedError struct {
+	Reason string `json:"reason" xml:"reason"`
+}
+
+func (e RenamedError) Error() string {
+	return e.Reason
+}
+
+func init() {
+	encoding.RegisterErrorAlias("svc.Renamed", RenamedError{})
+	encoding.RegisterErrorAlias("svc.OldName", RenamedError{})
+}
+
+func TestWrapErrorQualifiedTypeName(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{CustomDecodableError{}, "github.com/ayiga/go-kit-middlewarer/encoding_test.CustomDecodableError"},
+		{&CustomDecodableError{}, "*github.com/ayiga/go-kit-middlewarer/encoding_test.CustomDecodableError"},
+		{RenamedError{}, "svc.Renamed"},
+		{&RenamedError{}, "*svc.Renamed"},
+	}
+
+	for _, test := range tests {
+		if got := encoding.WrapError(test.err).Type; got != test.want {
+			t.Errorf("Type:\ngot:\n\t%s\nwant:\n\t%s", got, test.want)
+		}
+	}
+}
+
+func TestGetErrorInstanceNames(t *testing.T) {
+	tests := []struct {
+		name string
+		want reflect.Type
+	}{
+		{"github.com/ayiga/go-kit-middlewarer/encoding_test.CustomDecodableError", reflect.TypeOf(&CustomDecodableError{})},
+		// the names used by older versions are still understood.
+		{"encoding_test.CustomDecodableError", reflect.TypeOf(&CustomDecodableError{})},
+		{"*encoding_test.CustomDecodableError", reflect.TypeOf(&CustomDecodableError{})},
+		{"svc.Renamed", reflect.TypeOf(&RenamedError{})},
+		{"svc.OldName", reflect.TypeOf(&RenamedError{})},
+		{"github.com/ayiga/go-kit-middlewarer/encoding_test.RenamedError", reflect.TypeOf(&RenamedError{})},
+	}
+
+	for _, test := range tests {
+		instance, err := encoding.GetErrorInstance(test.name)
+		if err != nil {
+			t.Errorf("GetErrorInstance(%q): %s", test.name, err)
+			continue
+		}
+
+		if got := reflect.TypeOf(instance); got != test.want {
+			t.Errorf("GetErrorInstance(%q):\ngot:\n\t%s\nwant:\n\t%s", test.name, got, test.want)
+		}
+	}
+
+	if _, err := encoding.GetErrorInstance("encoding_test.Unregistered"); err != encoding.ErrUnknownError {
+		t.Errorf("GetErrorInstance:\ngot:\n\t%v\nwant:\n\t%v", err, encoding.ErrUnknownError)
+	}
+}
+
+func TestRegisterErrorAliasDuplicate(t *testing.T) {
+	if got, want := encoding.RegisterErrorAlias("svc.Renamed", NotFoundError{}), encoding.ErrDuplicate; got != want {
+		t.Errorf("RegisterErrorAlias:\ngot:\n\t%v\nwant:\n\t%v", got, want)
+	}
+}
+
+func TestAliasedErrorRoundTrip(t *testing.T) {
+	r := roundTripError(t, encoding.JSON(0), "application/json", RenamedError{Reason: "foo"})
+	if got, want := r, (RenamedError{Reason: "foo"}); !reflect.DeepEqual(got, want) {
+		t.Errorf("Error:\ngot:\n\t%#v\nwant:\n\t%#v", got, want)
+	}
+}
@@ -0,0 +1,114 @@
+package encoding
+
+import (
+	"net/http"
+	"reflect"
+
+	httptransport "github.com/go-kit/kit/transport/http"
+)
+
+var registeredErrorStatuses = make(map[string]int)
+var registeredSentinelStatuses = make(map[string]int)
+
+// RegisterErrorStatus will associate the type of the given error with the
+// given HTTP status code.  Errors of this type will be responded with using
+// the given status code, unless they implement
+// github.com/go-kit/kit/transport/http.StatusCoder themselves.
+//
+// If the given error has been registered with RegisterSentinel, the status
+// code is associated with that error value alone, rather than its type.
+//
+// Like RegisterError, this is expected to be called during initialization.
+func RegisterErrorStatus(e error, status int) error {
+	if e == nil {
+		// there is no type to associate the status code with
+		return ErrBlacklisted
+	}
+
+	if code := sentinelCode(e); code != "" {
+		if _, ok := registeredSentinelStatuses[code]; ok {
+			return ErrDuplicate
+		}
+
+		registeredSentinelStatuses[code] = status
+		return nil
+	}
+
+	t := reflect.TypeOf(e)
+	if reflect.TypeOf(ErrBlacklisted) == t {
+		return ErrBlacklisted
+	}
+
+	// ensure that we do not have a pointer
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if _, ok := registeredErrorStatuses[qualifiedTypeName(t)]; ok {
+		return ErrDuplicate
+	}
+
+	registeredErrorStatuses[qualifiedTypeName(t)] = status
+	return nil
+}
+
+// ErrorStatus returns the HTTP status code that should be used to respond with
+// the given error.  If the error implements
+// github.com/go-kit/kit/transport/http.StatusCoder its status code is used,
+// followed by the status code registered with RegisterErrorStatus.  If neither
+// exist, the errors it wraps are consulted in turn.  Otherwise the status will
+// be 500.
+func ErrorStatus(err error) int {
+	if status, ok := errorStatus(err); ok {
+		return status
+	}
+
+	return http.StatusInternalServerError
+}
+
+func errorStatus(err error) (int, bool) {
+	if err == nil {
+		return 0, false
+	}
+
+	if sc, ok := err.(httptransport.StatusCoder); ok {
+		return sc.StatusCode(), true
+	}
+
+	if status, ok := registeredSentinelStatuses[sentinelCode(err)]; ok {
+		return status, true
+	}
+
+	t := reflect.TypeOf(err)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if status, ok := registeredErrorStatuses[qualifiedTypeName(t)]; ok {
+		return status, true
+	}
+
+	for _, w := range unwrapErrors(err) {
+		if status, ok := errorStatus(w); ok {
+			return status, true
+		}
+	}
+
+	return 0, false
+}
+
+// errorHeaders will copy the headers of the given error onto the given
+// net/http.Header, if the error implements
+// github.com/go-kit/kit/transport/http.Headerer.
+func errorHeaders(err error, h http.Header) {
+	headerer, ok := err.(httptransport.Headerer)
+	if !ok {
+		return
+	}
+
+	for k, values := range headerer.Headers() {
+		for _, v := range values {
+			h.Add(k, v)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package encoding_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/ayiga/go-kit-middlewarer/encoding"
+)
+
+type NotFoundError struct {
+	Resource string `json:"resource"`
+}
+
+func (e NotFoundError) Error() string {
+	return e.Resource + " was not found"
+}
+
+type RetryAfterError struct{}
+
+func (RetryAfterError) Error() string {
+	return "try again later"
+}
+
+func (RetryAfterError) StatusCode() int {
+	return http.StatusServiceUnavailable
+}
+
+func (RetryAfterError) Headers() http.Header {
+	return http.Header{"Retry-After": []string{"120"}}
+}
+
+func init() {
+	encoding.RegisterError(NotFoundError{})
+	encoding.RegisterErrorStatus(NotFoundError{}, http.StatusNotFound)
+}
+
+func TestRegisterErrorStatusDuplicate(t *testing.T) {
+	if got, want := encoding.RegisterErrorStatus(&NotFoundError{}, http.StatusGone), encoding.ErrDuplicate; got != want {
+		t.Errorf("RegisterErrorStatus:\ngot:\n\t%v\nwant:\n\t%v", got, want)
+	}
+}
+
+func TestRegisterErrorStatusNil(t *testing.T) {
+	if got, want := encoding.RegisterErrorStatus(nil, http.StatusGone), encoding.ErrBlacklisted; got != want {
+		t.Errorf("RegisterErrorStatus:\ngot:\n\t%v\nwant:\n\t%v", got, want)
+	}
+}
+
+func TestErrorStatus(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{NotFoundError{}, http.StatusNotFound},
+		{&NotFoundError{}, http.StatusNotFound},
+		{RetryAfterError{}, http.StatusServiceUnavailable},
+		{encoding.ErrNotAcceptable, http.StatusNotAcceptable},
+		{http.ErrContentLength, http.StatusInternalServerError},
+	}
+
+	for _, test := range tests {
+		if got := encoding.ErrorStatus(test.err); got != test.want {
+			t.Errorf("ErrorStatus(%T):\ngot:\n\t%d\nwant:\n\t%d", test.err, got, test.want)
+		}
+	}
+}
+
+func TestErrorEncoderHeaders(t *testing.T) {
+	rec := httptest.NewRecorder()
+	encoding.MakeErrorEncoder(encoding.Default())(context.Background(), RetryAfterError{}, rec)
+
+	if got, want := rec.Code, http.StatusServiceUnavailable; got != want {
+		t.Errorf("Status:\ngot:\n\t%d\nwant:\n\t%d", got, want)
+	}
+
+	if got, want := rec.Header().Get("Retry-After"), "120"; got != want {
+		t.Errorf("Retry-After:\ngot:\n\t%s\nwant:\n\t%s", got, want)
+	}
+}
+
+func TestErrorStatusRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	rec := httptest.NewRecorder()
+	encoding.MakeErrorEncoder(encoding.Default())(ctx, NotFoundError{Resource: "foo"}, rec)
+
+	if got, want := rec.Code, http.StatusNotFound; got != want {
+		t.Fatalf("Status:\ngot:\n\t%d\nwant:\n\t%d", got, want)
+	}
+
+	ro := rec.Result()
+	resp := new(request)
+	r, err := encoding.Default().DecodeResponse(resp)(ctx, ro)
+	if err != nil {
+		t.Fatalf("Unable to Decode Response: %s", err)
+	}
+
+	if got, want := r, (NotFoundError{Resource: "foo"}); !reflect.DeepEqual(got, want) {
+		t.Errorf("Error:\ngot:\n\t%#v\nwant:\n\t%#v", got, want)
+	}
+}
+
+func TestUnregisteredErrorKeepsStatus(t *testing.T) {
+	ctx := context.Background()
+	rec := httptest.NewRecorder()
+	encoding.MakeErrorEncoder(encoding.Default())(ctx, RetryAfterError{}, rec)
+
+	ro := rec.Result()
+	ro.Body = ioutil.NopCloser(rec.Body)
+	resp := new(request)
+	r, err := encoding.Default().DecodeResponse(resp)(ctx, ro)
+	if err != nil {
+		t.Fatalf("Unable to Decode Response: %s", err)
+	}
+
+	we, ok := r.(encoding.WrapperError)
+	if !ok {
+		t.Fatalf("Type Of:\ngot:\n\t%T\nwant:\n\t%T", r, encoding.WrapperError{})
+	}
+
+	if got, want := encoding.ErrorStatus(we), http.StatusServiceUnavailable; got != want {
+		t.Errorf("ErrorStatus:\ngot:\n\t%d\nwant:\n\t%d", got, want)
+	}
+}
@@ -0,0 +1,23 @@
+package encoding
+
+import (
+	"compress/gzip"
+	"io"
+)
+
+func init() {
+	RegisterCompression("gzip", Gzip(0))
+}
+
+// Gzip is a simple gzip compressor / decompressor that conforms to Compressor
+type Gzip int
+
+// Compress implements Compressor
+func (Gzip) Compress(w io.Writer) (io.WriteCloser, error) {
+	return gzip.NewWriter(w), nil
+}
+
+// Decompress implements Compressor
+func (Gzip) Decompress(r io.Reader) (io.ReadCloser, error) {
+	return gzip.NewReader(r)
+}
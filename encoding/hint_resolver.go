@@ -1,113 +1,154 @@
 package encoding
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"errors"
 	"io"
-	"io/ioutil"
 	"net/http"
+	"unicode"
+	"unicode/utf8"
 
 	httptransport "github.com/go-kit/kit/transport/http"
 )
 
 var ErrEmptyRequest = errors.New("Empty Request, nothing to sniff")
+var ErrEmptyResponse = errors.New("Empty Response, nothing to sniff")
 
-func copyRequestToBuf(r *http.Request) ([]byte, error) {
-	buf := new(bytes.Buffer)
-	var i int64 = 0
-	for i < r.ContentLength {
-		l, err := io.CopyN(buf, r.Body, r.ContentLength)
-		if err != nil {
-			return nil, err
+// sniffLen is the maximum number of bytes that will be inspected in order to
+// find the first significant rune of a body.
+const sniffLen = 512
+
+var utf8BOM = []byte{0xef, 0xbb, 0xbf}
+
+// sniffer wraps a body so that the first bytes can be inspected without
+// consuming them.  Everything read from the body is recorded, so that it can
+// be replayed for each subsequent decode attempt.
+type sniffer struct {
+	body     io.ReadCloser
+	br       *bufio.Reader
+	consumed bytes.Buffer
+}
+
+func newSniffer(body io.ReadCloser) *sniffer {
+	return &sniffer{
+		body: body,
+		br:   bufio.NewReaderSize(body, sniffLen),
+	}
+}
+
+// firstRune returns the first rune of the body that isn't whitespace, after
+// discarding any byte order mark.  io.EOF is returned if the body is empty.
+func (s *sniffer) firstRune() (rune, error) {
+	if p, _ := s.br.Peek(len(utf8BOM)); bytes.Equal(p, utf8BOM) {
+		s.br.Discard(len(utf8BOM))
+	}
+
+	p, err := s.br.Peek(sniffLen)
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return 0, err
+	}
+
+	p = bytes.TrimLeftFunc(p, unicode.IsSpace)
+	if len(p) == 0 {
+		if err == io.EOF {
+			return 0, io.EOF
 		}
-		i += l
+		return 0, ErrUnableToDetermineMime
 	}
 
-	// replace the body, though thisi s probably not necessary
-	return buf.Bytes(), nil
+	r, _ := utf8.DecodeRune(p)
+	return r, nil
 }
 
-func copyResponseToBuf(r *http.Response) ([]byte, error) {
-	buf := new(bytes.Buffer)
-	var i int64 = 0
-	for i < r.ContentLength {
-		l, err := io.CopyN(buf, r.Body, r.ContentLength)
-		if err != nil {
-			return nil, err
-		}
-		i += l
+// replay returns a body that will first re-read everything that has been
+// consumed by previous attempts, before continuing on with the remainder.
+func (s *sniffer) replay() io.ReadCloser {
+	return replayReadCloser{
+		Reader: io.MultiReader(bytes.NewReader(s.consumed.Bytes()), io.TeeReader(s.br, &s.consumed)),
+		Closer: s.body,
 	}
+}
 
-	// replace the body, though thisi s probably not necessary
-	return buf.Bytes(), nil
+type replayReadCloser struct {
+	io.Reader
+	io.Closer
 }
 
-type hintResolver int
+type hintResolver struct {
+	registry *Registry
+}
+
+// candidates returns the mime types to attempt to decode with, in order of
+// priority.  Mime types whose start hints match the given rune come first,
+// followed by the mime types that have no start hints at all.  Within each
+// group the DefaultEncoding comes first, and the rest are in the order they
+// were registered.
+func (hr hintResolver) candidates(first rune) []string {
+	mimes := hr.registry.List()
+	for i, mime := range mimes {
+		if mime == DefaultEncoding {
+			mimes = append([]string{mime}, append(mimes[:i:i], mimes[i+1:]...)...)
+			break
+		}
+	}
+
+	var hinted, unhinted []string
+	for _, mime := range mimes {
+		hints := hr.registry.firstRunes(mime)
+		if len(hints) == 0 {
+			unhinted = append(unhinted, mime)
+			continue
+		}
+
+		for _, hint := range hints {
+			if hint == first {
+				hinted = append(hinted, mime)
+				break
+			}
+		}
+	}
+
+	return append(hinted, unhinted...)
+}
 
 // EncodeRequest does not implement RequestResponseEncoding
-func (hintResolver) EncodeRequest() httptransport.EncodeRequestFunc {
+func (hr hintResolver) EncodeRequest() httptransport.EncodeRequestFunc {
 	return func(ctx context.Context, r *http.Request, request interface{}) error {
 		return ErrNotImplemented
 	}
 }
 
 // DecodeRequest implements RequestResponseEncoding
-func (hintResolver) DecodeRequest(request interface{}) httptransport.DecodeRequestFunc {
+func (hr hintResolver) DecodeRequest(request interface{}) httptransport.DecodeRequestFunc {
 	return func(ctx context.Context, r *http.Request) (interface{}, error) {
-		byts, err := copyRequestToBuf(r)
-		if err != nil {
-			return request, err
+		if r.Body == nil {
+			return request, ErrEmptyRequest
 		}
 
-		if len(byts) <= 0 {
+		s := newSniffer(r.Body)
+		first, err := s.firstRune()
+		if err == io.EOF {
 			// we have an issue, nothing to detect
 			return request, ErrEmptyRequest
+		} else if err != nil {
+			return request, err
 		}
 
-		rune1 := []rune(string(byts))[0]
-
-		var mimesToSkip = map[string]bool{}
-		for mime, hints := range mimeToFirstRunes {
-			for _, rune2 := range hints {
-				mimesToSkip[mime] = true
-				if rune1 == rune2 {
-					encoding, err := Get(mime)
-					if err != nil {
-						// not found... this should be impossible
-						// but it's good to check it anyway.
-						break
-					}
-
-					r.Body = ioutil.NopCloser(bytes.NewBuffer(byts))
-
-					if _, err = encoding.DecodeRequest(request)(ctx, r); err != nil {
-						// encoding failed... let's retry
-						break
-					}
-
-					if em, ok := request.(EmbededMime); ok {
-						// let's embed the mime type
-						accept := parseAccept(r.Header.Get("Accept"))
-						ct := parseContentType(mime)
-						transferMimeDetails(em, ct, accept)
-					}
-
-					// we succeeded
-					return request, nil
-				}
-			}
-		}
-
-		// well... I guess we'll just try all of them, on at a time...
-		for mime, encoding := range mimeToEncodings {
-			if mimesToSkip[mime] {
+		for _, mime := range hr.candidates(first) {
+			encoding, err := hr.registry.Get(mime)
+			if err != nil {
+				// unregistered since we've listed them.
 				continue
 			}
 
-			r.Body = ioutil.NopCloser(bytes.NewBuffer(byts))
-
-			if _, err = encoding.DecodeRequest(request)(ctx, r); err != nil {
+			r.Body = s.replay()
+			if _, err = encoding.DecodeRequest(request)(ctx, r); err == ErrRequestEntityTooLarge || err == ErrMaxDepthExceeded {
+				// no other encoding will fare any better
+				return request, err
+			} else if err != nil {
+				// encoding failed... let's try the next one
 				continue
 			}
 
@@ -115,7 +156,9 @@ func (hintResolver) DecodeRequest(request interface{}) httptransport.DecodeReque
 				// let's embed the mime type
 				accept := parseAccept(r.Header.Get("Accept"))
 				ct := parseContentType(mime)
-				transferMimeDetails(em, ct, accept)
+				if err := transferMimeDetails(ctx, hr.registry, em, ct, accept); err != nil {
+					return request, err
+				}
 			}
 
 			// we succeeded
@@ -127,69 +170,47 @@ func (hintResolver) DecodeRequest(request interface{}) httptransport.DecodeReque
 }
 
 // EncodeResponse does not implement RequestResponseEncoding
-func (hintResolver) EncodeResponse() httptransport.EncodeResponseFunc {
+func (hr hintResolver) EncodeResponse() httptransport.EncodeResponseFunc {
 	return func(ctx context.Context, w http.ResponseWriter, response interface{}) error {
 		return ErrNotImplemented
 	}
 }
 
 // DecodeResponse implements RequestResponseEncoding
-func (hintResolver) DecodeResponse(response interface{}) httptransport.DecodeResponseFunc {
+func (hr hintResolver) DecodeResponse(response interface{}) httptransport.DecodeResponseFunc {
 	return func(ctx context.Context, r *http.Response) (interface{}, error) {
-		byts, err := copyResponseToBuf(r)
-		if err != nil {
-			return response, err
+		if r.Body == nil {
+			return response, ErrEmptyResponse
 		}
 
-		rune1 := []rune(string(byts))[0]
-
-		var mimesToSkip = map[string]bool{}
-
-		for mime, hints := range mimeToFirstRunes {
-			for _, rune2 := range hints {
-				mimesToSkip[mime] = true
-				if rune1 == rune2 {
-					encoding, err := Get(mime)
-					if err != nil {
-						// not found... this should be impossible
-						// but it's good to check it anyway.
-						break
-					}
-
-					r.Body = ioutil.NopCloser(bytes.NewBuffer(byts))
-
-					if _, err = encoding.DecodeResponse(response)(ctx, r); err != nil {
-						// encoding failed... let's retry
-						break
-					}
-					// we succeeded
-					return response, nil
-				}
-			}
+		s := newSniffer(r.Body)
+		first, err := s.firstRune()
+		if err == io.EOF {
+			return response, ErrEmptyResponse
+		} else if err != nil {
+			return response, err
 		}
 
-		// well... I guess we'll just try all of them, on at a time...
-		for mime, encoding := range mimeToEncodings {
-			if mimesToSkip[mime] {
+		for _, mime := range hr.candidates(first) {
+			encoding, err := hr.registry.Get(mime)
+			if err != nil {
 				continue
 			}
 
-			r.Body = ioutil.NopCloser(bytes.NewBuffer(byts))
-
-			if _, err = encoding.DecodeResponse(response)(ctx, r); err != nil {
+			r.Body = s.replay()
+			result, err := encoding.DecodeResponse(response)(ctx, r)
+			if err != nil {
 				// error decoding, it's likely not this mime type.
 				continue
 			}
 
 			if em, ok := response.(EmbededMime); ok {
 				// let's embed the mime type
-				accept := parseAccept(r.Header.Get("Accept"))
-				ct := parseContentType(mime)
-				transferMimeDetails(em, ct, accept)
+				em.SetMime(mime)
 			}
 
 			// we succeeded
-			return response, nil
+			return result, nil
 		}
 
 		return response, ErrUnableToDetermineMime
@@ -0,0 +1,32 @@
+package encoding
+
+import (
+	"encoding/gob"
+	"fmt"
+	"net/http"
+)
+
+func init() {
+	gob.Register(InternalError{})
+	RegisterError(InternalError{})
+}
+
+// InternalError is returned in place of a panic that has been recovered
+// from, so that it is responded with like any other error, as a 500 Internal
+// Server Error, rather than an empty response.
+//
+// The Message is a generic one, unless the recovery was configured to expose
+// the value the method panicked with.
+type InternalError struct {
+	Path    string `json:"path" xml:"path"`
+	Message string `json:"message" xml:"message"`
+}
+
+func (e InternalError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Path, e.Message)
+}
+
+// StatusCode implements github.com/go-kit/kit/transport/http.StatusCoder
+func (e InternalError) StatusCode() int {
+	return http.StatusInternalServerError
+}
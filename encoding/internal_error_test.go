@@ -0,0 +1,32 @@
+package encoding_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ayiga/go-kit-middlewarer/encoding"
+)
+
+func TestInternalErrorRoundTrip(t *testing.T) {
+	ie := encoding.InternalError{Path: "/uppercase", Message: "panic recovered"}
+
+	for _, ctx := range []context.Context{context.Background(), problemContext(encoding.ProblemJSONMime)} {
+		rec := httptest.NewRecorder()
+		encoding.MakeErrorEncoder(encoding.Default())(ctx, ie, rec)
+
+		if got, want := rec.Code, http.StatusInternalServerError; got != want {
+			t.Errorf("Status:\ngot:\n\t%d\nwant:\n\t%d", got, want)
+		}
+
+		r, err := encoding.Default().DecodeResponse(new(request))(ctx, rec.Result())
+		if err != nil {
+			t.Fatalf("Unable to Decode Response: %s", err)
+		}
+
+		if got, want := r, interface{}(ie); got != want {
+			t.Errorf("Error:\ngot:\n\t%#v\nwant:\n\t%#v", got, want)
+		}
+	}
+}
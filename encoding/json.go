@@ -1,8 +1,10 @@
 package encoding
 
 import (
+	"context"
 	"encoding/json"
 	"io"
+	"net/http"
 
 	httptransport "github.com/go-kit/kit/transport/http"
 )
@@ -33,7 +35,13 @@ func (JSON) EncodeRequest() httptransport.EncodeRequestFunc {
 
 // DecodeRequest implements RequestResponseEncoding
 func (JSON) DecodeRequest(request interface{}) httptransport.DecodeRequestFunc {
-	return MakeRequestDecoder(request, JSONGenerateDecoder)
+	dec := MakeRequestDecoder(request, JSONGenerateDecoder)
+	return func(ctx context.Context, r *http.Request) (interface{}, error) {
+		if max := limitsFromContext(ctx).MaxJSONDepth; max > 0 {
+			r.Body = limitJSONDepth(r.Body, max)
+		}
+		return dec(ctx, r)
+	}
 }
 
 // EncodeResponse implements RequestResponseEncoding
@@ -0,0 +1,55 @@
+package encoding
+
+import (
+	"encoding/gob"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	kitratelimit "github.com/go-kit/kit/ratelimit"
+)
+
+func init() {
+	gob.Register(LimitedError{})
+	RegisterError(LimitedError{})
+	RegisterSentinel("github.com/go-kit/kit/ratelimit.ErrLimited", kitratelimit.ErrLimited)
+}
+
+// LimitedError is returned when a request has been rejected, as its method
+// has exceeded its rate limit.  It is responded with as a 429 Too Many
+// Requests, with a Retry-After header, and unwraps to
+// github.com/go-kit/kit/ratelimit.ErrLimited.
+type LimitedError struct {
+	Path       string        `json:"path" xml:"path"`
+	RetryAfter time.Duration `json:"retryAfter" xml:"retry-after"`
+}
+
+func (e LimitedError) Error() string {
+	if e.RetryAfter <= 0 {
+		return fmt.Sprintf("%s: %s", e.Path, kitratelimit.ErrLimited)
+	}
+
+	return fmt.Sprintf("%s: %s, retry after %s", e.Path, kitratelimit.ErrLimited, e.RetryAfter)
+}
+
+// Unwrap allows errors.Is to match github.com/go-kit/kit/ratelimit.ErrLimited
+func (e LimitedError) Unwrap() error {
+	return kitratelimit.ErrLimited
+}
+
+// StatusCode implements github.com/go-kit/kit/transport/http.StatusCoder
+func (e LimitedError) StatusCode() int {
+	return http.StatusTooManyRequests
+}
+
+// Headers implements github.com/go-kit/kit/transport/http.Headerer
+func (e LimitedError) Headers() http.Header {
+	h := make(http.Header)
+	if e.RetryAfter > 0 {
+		h.Set("Retry-After", strconv.Itoa(int(math.Ceil(e.RetryAfter.Seconds()))))
+	}
+
+	return h
+}
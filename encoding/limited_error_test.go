@@ -0,0 +1,44 @@
+package encoding_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	kitratelimit "github.com/go-kit/kit/ratelimit"
+
+	"github.com/ayiga/go-kit-middlewarer/encoding"
+)
+
+func TestLimitedErrorRoundTrip(t *testing.T) {
+	le := encoding.LimitedError{Path: "/uppercase", RetryAfter: 1500 * time.Millisecond}
+
+	for _, ctx := range []context.Context{context.Background(), problemContext(encoding.ProblemJSONMime)} {
+		rec := httptest.NewRecorder()
+		encoding.MakeErrorEncoder(encoding.Default())(ctx, le, rec)
+
+		if got, want := rec.Code, http.StatusTooManyRequests; got != want {
+			t.Errorf("Status:\ngot:\n\t%d\nwant:\n\t%d", got, want)
+		}
+		if got, want := rec.Header().Get("Retry-After"), "2"; got != want {
+			t.Errorf("Retry-After:\ngot:\n\t%s\nwant:\n\t%s", got, want)
+		}
+
+		r, err := encoding.Default().DecodeResponse(new(request))(ctx, rec.Result())
+		if err != nil {
+			t.Fatalf("Unable to Decode Response: %s", err)
+		}
+
+		// the client receives the typed error, so RetryAfter can be read
+		got, ok := r.(encoding.LimitedError)
+		if !ok || got != le {
+			t.Fatalf("Error:\ngot:\n\t%#v\nwant:\n\t%#v", r, le)
+		}
+		if !errors.Is(got, kitratelimit.ErrLimited) {
+			t.Errorf("errors.Is(err, ErrLimited) = false")
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package encoding
+
+import (
+	"context"
+	"io"
+	"net/http"
+
+	httptransport "github.com/go-kit/kit/transport/http"
+)
+
+// Limits represents the restrictions placed upon decoding Requests.  The zero
+// value of each field disables that restriction.
+type Limits struct {
+	// MaxBodySize represents the maximum number of bytes that will be read
+	// from a Request body.  If the body is compressed, this applies to the
+	// decompressed body.
+	MaxBodySize int64
+
+	// MaxJSONDepth represents the maximum number of nested JSON objects and
+	// arrays that a Request body may contain.
+	MaxJSONDepth int
+
+	// DisallowUnknownFields causes the Decoder to reject Request bodies that
+	// contain fields which do not exist within the Request structure.  This is
+	// only honored by Decoders that support it, such as encoding/json.
+	DisallowUnknownFields bool
+}
+
+// DefaultLimits are the Limits used when none have been specified via
+// SetLimits.
+var DefaultLimits = Limits{
+	MaxBodySize:  32 << 20,
+	MaxJSONDepth: 1000,
+}
+
+// SetLimits returns a github.com/go-kit/kit/transport/http.RequestFunc that
+// specifies the Limits to enforce while decoding the Request.
+func SetLimits(limits Limits) httptransport.RequestFunc {
+	return func(ctx context.Context, r *http.Request) context.Context {
+		return context.WithValue(ctx, contextKeyLimits, limits)
+	}
+}
+
+func limitsFromContext(ctx context.Context) Limits {
+	if limits, ok := ctx.Value(contextKeyLimits).(Limits); ok {
+		return limits
+	}
+
+	return DefaultLimits
+}
+
+// limitedBody will return ErrRequestEntityTooLarge once more than max bytes
+// have been read.
+type limitedBody struct {
+	io.ReadCloser
+	remaining int64
+	exceeded  bool
+}
+
+func limitBody(body io.ReadCloser, max int64) *limitedBody {
+	return &limitedBody{
+		ReadCloser: body,
+		remaining:  max,
+	}
+}
+
+// Read implements io.Reader
+func (lb *limitedBody) Read(p []byte) (int, error) {
+	if lb.exceeded {
+		return 0, ErrRequestEntityTooLarge
+	}
+
+	// read one more than is allowed, so we know whether the limit has been
+	// exceeded, rather than just met.
+	if int64(len(p)) > lb.remaining+1 {
+		p = p[:lb.remaining+1]
+	}
+
+	n, err := lb.ReadCloser.Read(p)
+	if int64(n) > lb.remaining {
+		lb.exceeded = true
+		n = int(lb.remaining)
+		err = ErrRequestEntityTooLarge
+	}
+	lb.remaining -= int64(n)
+	return n, err
+}
+
+// jsonDepthReader will return ErrMaxDepthExceeded once the JSON read through it
+// nests objects and arrays deeper than allowed.  It only tracks enough of the
+// JSON grammar to know when it is within a string.
+type jsonDepthReader struct {
+	io.ReadCloser
+	max      int
+	depth    int
+	inString bool
+	escaped  bool
+}
+
+// Read implements io.Reader
+func (jdr *jsonDepthReader) Read(p []byte) (int, error) {
+	n, err := jdr.ReadCloser.Read(p)
+	for _, b := range p[:n] {
+		switch {
+		case jdr.escaped:
+			jdr.escaped = false
+		case jdr.inString && b == '\\':
+			jdr.escaped = true
+		case b == '"':
+			jdr.inString = !jdr.inString
+		case jdr.inString:
+		case b == '{' || b == '[':
+			jdr.depth++
+			if jdr.depth > jdr.max {
+				return 0, ErrMaxDepthExceeded
+			}
+		case b == '}' || b == ']':
+			jdr.depth--
+		}
+	}
+	return n, err
+}
+
+func limitJSONDepth(body io.ReadCloser, max int) io.ReadCloser {
+	return &jsonDepthReader{
+		ReadCloser: body,
+		max:        max,
+	}
+}
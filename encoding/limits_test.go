@@ -0,0 +1,115 @@
+package encoding_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ayiga/go-kit-middlewarer/encoding"
+)
+
+func limitsContext(limits encoding.Limits) context.Context {
+	return encoding.SetLimits(limits)(context.Background(), nil)
+}
+
+func decodeLimitedRequest(ctx context.Context, contentType, body string) error {
+	r, err := http.NewRequest("POST", "/not/important", strings.NewReader(body))
+	if err != nil {
+		panic(err)
+	}
+	if contentType != "" {
+		r.Header.Set("Content-Type", contentType)
+	}
+	// simulate a chunked body, so the limit can't be enforced up front.
+	r.ContentLength = -1
+
+	req := new(request)
+	req.embedMime = new(embedMime)
+	_, err = encoding.Default().DecodeRequest(req)(ctx, r)
+	return err
+}
+
+func TestDecodeRequestMaxBodySize(t *testing.T) {
+	ctx := limitsContext(encoding.Limits{MaxBodySize: 16})
+	body := "{\"str\":\"" + strings.Repeat("a", 32) + "\"}"
+
+	for _, ct := range []string{"application/json", "application/xml", ""} {
+		if err := decodeLimitedRequest(ctx, ct, body); err != encoding.ErrRequestEntityTooLarge {
+			t.Errorf("Content-Type %q:\ngot:\n\t%v\nwant:\n\t%v", ct, err, encoding.ErrRequestEntityTooLarge)
+		}
+	}
+
+	if err := decodeLimitedRequest(ctx, "application/json", "{\"str\":\"a\"}"); err != nil {
+		t.Errorf("Unable to Decode Request within limits: %s", err)
+	}
+}
+
+func TestDecodeRequestContentLengthTooLarge(t *testing.T) {
+	ctx := limitsContext(encoding.Limits{MaxBodySize: 16})
+	r, err := http.NewRequest("POST", "/not/important", strings.NewReader("{\"str\":\"aaaaaaaaaaaaaaaaaaaa\"}"))
+	if err != nil {
+		panic(err)
+	}
+	r.Header.Set("Content-Type", "application/json")
+
+	req := new(request)
+	req.embedMime = new(embedMime)
+	if _, err := encoding.Default().DecodeRequest(req)(ctx, r); err != encoding.ErrRequestEntityTooLarge {
+		t.Errorf("Decode:\ngot:\n\t%v\nwant:\n\t%v", err, encoding.ErrRequestEntityTooLarge)
+	}
+}
+
+func TestDecodeRequestMaxJSONDepth(t *testing.T) {
+	ctx := limitsContext(encoding.Limits{MaxJSONDepth: 3})
+
+	// brackets within strings do not count towards the depth.
+	if err := decodeLimitedRequest(ctx, "application/json", "{\"str\":\"[[[[[[\"}"); err != nil {
+		t.Errorf("Unable to Decode Request within limits: %s", err)
+	}
+
+	body := "{\"str\":\"a\",\"extra\":[[[[1]]]]}"
+	if err := decodeLimitedRequest(ctx, "application/json", body); err != encoding.ErrMaxDepthExceeded {
+		t.Errorf("Decode:\ngot:\n\t%v\nwant:\n\t%v", err, encoding.ErrMaxDepthExceeded)
+	}
+}
+
+func TestDecodeRequestDisallowUnknownFields(t *testing.T) {
+	body := "{\"str\":\"a\",\"unknown\":true}"
+
+	if err := decodeLimitedRequest(context.Background(), "application/json", body); err != nil {
+		t.Errorf("Unable to Decode Request by default: %s", err)
+	}
+
+	ctx := limitsContext(encoding.Limits{DisallowUnknownFields: true})
+	if err := decodeLimitedRequest(ctx, "application/json", body); err == nil {
+		t.Errorf("Decode Request with unknown fields succeeded")
+	}
+}
+
+func TestErrorEncoderStatusCode(t *testing.T) {
+	rec := httptest.NewRecorder()
+	encoding.MakeErrorEncoder(encoding.Default())(context.Background(), encoding.ErrRequestEntityTooLarge, rec)
+
+	if got, want := rec.Code, http.StatusRequestEntityTooLarge; got != want {
+		t.Errorf("Status:\ngot:\n\t%d\nwant:\n\t%d", got, want)
+	}
+
+	var we encoding.WrapperError
+	if err := json.NewDecoder(bytes.NewReader(rec.Body.Bytes())).Decode(&we); err != nil {
+		t.Fatalf("Unable to Decode WrapperError: %s", err)
+	}
+
+	if got, want := we.Error(), encoding.ErrRequestEntityTooLarge.Error(); got != want {
+		t.Errorf("Error:\ngot:\n\t%s\nwant:\n\t%s", got, want)
+	}
+
+	rec = httptest.NewRecorder()
+	encoding.MakeErrorEncoder(encoding.Default())(context.Background(), encoding.ErrMimeNotFound, rec)
+	if got, want := rec.Code, http.StatusInternalServerError; got != want {
+		t.Errorf("Status:\ngot:\n\t%d\nwant:\n\t%d", got, want)
+	}
+}
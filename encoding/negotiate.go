@@ -0,0 +1,201 @@
+package encoding
+
+import (
+	"context"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	httptransport "github.com/go-kit/kit/transport/http"
+)
+
+// mediaRange represents a single media-range of an Accept header, as
+// described by RFC 9110 Section 12.5.1.
+type mediaRange struct {
+	typ     string
+	subtype string
+	params  map[string]string
+	q       float32
+}
+
+// specificity returns how specific this media-range is.  More specific
+// media-ranges override less specific ones.
+func (mr mediaRange) specificity() int {
+	switch {
+	case mr.typ == "*":
+		return 0
+	case mr.subtype == "*":
+		return 1
+	case len(mr.params) == 0:
+		return 2
+	default:
+		return 3
+	}
+}
+
+// matches returns whether the given media type, without parameters, falls
+// within this media-range.
+func (mr mediaRange) matches(mediaType string) bool {
+	pieces := strings.SplitN(mediaType, "/", 2)
+	if len(pieces) != 2 {
+		return false
+	}
+
+	if mr.typ == "*" {
+		return true
+	}
+
+	if mr.typ != pieces[0] {
+		return false
+	}
+
+	return mr.subtype == "*" || mr.subtype == pieces[1]
+}
+
+// (\w+\/\w+[;q=score],?)+
+type acceptContentHeader struct {
+	ranges []mediaRange
+}
+
+func parseAccept(accept string) acceptContentHeader {
+	var ranges []mediaRange
+
+	for _, p := range strings.Split(accept, ",") {
+		if strings.TrimSpace(p) == "" {
+			continue
+		}
+
+		mediaType, params, err := mime.ParseMediaType(p)
+		if err != nil {
+			continue
+		}
+
+		pieces := strings.SplitN(mediaType, "/", 2)
+		if len(pieces) != 2 || (pieces[0] == "*" && pieces[1] != "*") {
+			continue
+		}
+
+		var q float32 = 1.0
+		if v, ok := params["q"]; ok {
+			f, err := strconv.ParseFloat(v, 32)
+			if err != nil || f < 0 || f > 1 {
+				// an invalid weight invalidates the whole media-range
+				continue
+			}
+			q = float32(f)
+			delete(params, "q")
+		}
+
+		ranges = append(ranges, mediaRange{
+			typ:     pieces[0],
+			subtype: pieces[1],
+			params:  params,
+			q:       q,
+		})
+	}
+
+	return acceptContentHeader{
+		ranges: ranges,
+	}
+}
+
+// quality returns the weight given to the media type by the most specific
+// media-range that matches it.  If nothing matches, the media type is not
+// acceptable, and zero is returned.
+func (a acceptContentHeader) quality(mediaType string) float32 {
+	var q float32
+	var specificity = -1
+	for _, mr := range a.ranges {
+		if !mr.matches(mediaType) {
+			continue
+		}
+
+		if s := mr.specificity(); s > specificity {
+			specificity = s
+			q = mr.q
+		}
+	}
+
+	return q
+}
+
+// negotiate will return the most acceptable media type out of the given
+// candidates.  Ties between candidates are given to whichever candidate comes
+// first.  If nothing is acceptable, ErrNotAcceptable is returned.
+func (a acceptContentHeader) negotiate(candidates []string) (string, error) {
+	var score float32
+	var max = ""
+	for _, c := range candidates {
+		if q := a.quality(c); q > score {
+			score = q
+			max = c
+		}
+	}
+
+	if max == "" {
+		return "", ErrNotAcceptable
+	}
+
+	return max, nil
+}
+
+// SetMimePreference returns a github.com/go-kit/kit/transport/http.RequestFunc
+// that specifies the order in which the server prefers to respond with the
+// given mime types.  The preference is used to break ties between the mime
+// types the client has deemed equally acceptable.
+func SetMimePreference(mimes ...string) httptransport.RequestFunc {
+	return func(ctx context.Context, r *http.Request) context.Context {
+		return context.WithValue(ctx, contextKeyMimePreference, mimes)
+	}
+}
+
+// candidateMimes returns every registered mime type, in the order the server
+// would prefer to respond with them.  After the configured preference, the
+// Content-Type of the Request, and the DefaultEncoding, the order in which
+// the client listed them is used.
+func candidateMimes(ctx context.Context, reg *Registry, ct contentTypeValue, accept acceptContentHeader) []string {
+	var candidates []string
+	var seen = map[string]bool{}
+	add := func(mimes ...string) {
+		for _, m := range mimes {
+			if seen[m] {
+				continue
+			}
+			if _, err := reg.Get(m); err != nil {
+				continue
+			}
+			seen[m] = true
+			candidates = append(candidates, m)
+		}
+	}
+
+	if preference, ok := ctx.Value(contextKeyMimePreference).([]string); ok {
+		add(preference...)
+	}
+
+	add(ct.contentType, DefaultEncoding)
+	for _, mr := range accept.ranges {
+		add(mr.typ + "/" + mr.subtype)
+	}
+	add(reg.List()...)
+	return candidates
+}
+
+// transferMimeDetails will negotiate which mime type to respond with, and will
+// store it within the given EmbededMime.
+func transferMimeDetails(ctx context.Context, reg *Registry, em EmbededMime, ct contentTypeValue, accept acceptContentHeader) error {
+	if len(accept.ranges) == 0 {
+		// no preference was expressed, so we'll respond in kind.
+		em.SetMime(ct.contentType)
+		return nil
+	}
+
+	mime, err := accept.negotiate(candidateMimes(ctx, reg, ct, accept))
+	if err != nil {
+		return err
+	}
+
+	em.SetMime(mime)
+	return nil
+}
@@ -0,0 +1,200 @@
+package encoding
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+)
+
+const (
+	// ProblemJSONMime is the mime type of Problem Details, as described by
+	// RFC 9457, encoded as JSON.
+	ProblemJSONMime = "application/problem+json"
+
+	// ProblemXMLMime is the mime type of Problem Details, as described by
+	// RFC 9457, encoded as XML.
+	ProblemXMLMime = "application/problem+xml"
+)
+
+// problem represents the members of Problem Details that are defined by
+// RFC 9457.  The type member holds the registered error type, and the detail
+// member holds the error string.  The fields of the error itself, if its type
+// has been registered with RegisterError, are added as extension members.
+// The code member is added for errors registered with RegisterSentinel.
+// Should an extension member share its name with one of these members, it
+// will be omitted.
+type problem struct {
+	Type   string `json:"type" xml:"type"`
+	Title  string `json:"title,omitempty" xml:"title,omitempty"`
+	Status int    `json:"status,omitempty" xml:"status,omitempty"`
+	Detail string `json:"detail,omitempty" xml:"detail,omitempty"`
+	Code   string `json:"code,omitempty" xml:"code,omitempty"`
+}
+
+func newProblem(err error, status int) (problem, interface{}) {
+	we := WrapError(err)
+	return problem{
+		Type:   we.Type,
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: we.ErrString,
+		Code:   we.Code,
+	}, we.Err
+}
+
+// problemMime returns which Problem Details mime type the client has
+// explicitly asked for within its Accept header, if any.  Wildcards are not
+// considered, as a client matching */* is not expecting Problem Details.
+func problemMime(ctx context.Context) string {
+	header, _ := ctx.Value(contextKeyAccept).(string)
+	if header == "" {
+		return ""
+	}
+
+	var mime string
+	var score float32
+	for _, mr := range parseAccept(header).ranges {
+		mediaType := mr.typ + "/" + mr.subtype
+		if (mediaType == ProblemJSONMime || mediaType == ProblemXMLMime) && mr.q > score {
+			mime = mediaType
+			score = mr.q
+		}
+	}
+
+	return mime
+}
+
+// encodeProblem writes the given error to the ResponseWriter as Problem
+// Details of the given mime type.
+func encodeProblem(w http.ResponseWriter, mime string, err error, status int) error {
+	w.Header().Set("Content-Type", mime)
+	p, payload := newProblem(err, status)
+	if mime == ProblemXMLMime {
+		return encodeProblemXML(w, p, payload)
+	}
+
+	return encodeProblemJSON(w, p, payload)
+}
+
+func encodeProblemJSON(w io.Writer, p problem, payload interface{}) error {
+	members := map[string]interface{}{}
+	if payload != nil {
+		if b, err := json.Marshal(payload); err == nil {
+			var extensions map[string]json.RawMessage
+			// errors that are not JSON objects have no members to extend with.
+			if json.Unmarshal(b, &extensions) == nil {
+				for k, v := range extensions {
+					members[k] = v
+				}
+			}
+		}
+	}
+
+	b, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+
+	var standard map[string]json.RawMessage
+	if err := json.Unmarshal(b, &standard); err != nil {
+		return err
+	}
+
+	for k, v := range standard {
+		members[k] = v
+	}
+
+	return json.NewEncoder(w).Encode(members)
+}
+
+// anyElement represents an arbitrary XML element, preserved as is.
+type anyElement struct {
+	XMLName xml.Name
+	Attrs   []xml.Attr `xml:",any,attr"`
+	Inner   []byte     `xml:",innerxml"`
+}
+
+type problemXML struct {
+	XMLName xml.Name `xml:"urn:ietf:rfc:7807 problem"`
+	problem
+	Extensions []anyElement `xml:",any"`
+}
+
+func encodeProblemXML(w io.Writer, p problem, payload interface{}) error {
+	px := problemXML{problem: p}
+	if payload != nil {
+		if b, err := xml.Marshal(payload); err == nil {
+			var children struct {
+				Elements []anyElement `xml:",any"`
+			}
+			if xml.Unmarshal(b, &children) == nil {
+				for _, e := range children.Elements {
+					switch e.XMLName.Local {
+					case "type", "title", "status", "detail", "code":
+						continue
+					}
+					px.Extensions = append(px.Extensions, e)
+				}
+			}
+		}
+	}
+
+	return xml.NewEncoder(w).Encode(px)
+}
+
+// decodeProblem reads Problem Details of the given mime type from the body,
+// and returns the error they represent.  If the error has been registered with
+// RegisterSentinel, the very same error is returned.  If the error type has
+// been registered with RegisterError, an instance of that type is returned,
+// otherwise a WrapperError is returned.
+func decodeProblem(mime string, body io.Reader, status int) (error, error) {
+	b, err := ioutil.ReadAll(body)
+	if err != nil {
+		return nil, err
+	}
+
+	unmarshal := json.Unmarshal
+	var p problem
+	if mime == ProblemXMLMime {
+		unmarshal = xml.Unmarshal
+		var px problemXML
+		if err := xml.Unmarshal(b, &px); err != nil {
+			return nil, err
+		}
+		p = px.problem
+	} else if err := json.Unmarshal(b, &p); err != nil {
+		return nil, err
+	}
+
+	if p.Status != 0 {
+		status = p.Status
+	}
+
+	if sentinel, ok := registeredSentinels[p.Code]; ok {
+		return sentinel, nil
+	}
+
+	if instance, err := GetErrorInstance(p.Type); err == nil {
+		if unmarshal(b, instance) == nil {
+			if e, ok := reflect.Indirect(reflect.ValueOf(instance)).Interface().(error); ok {
+				return e, nil
+			}
+		}
+	}
+
+	return WrapperError{
+		Type:      p.Type,
+		ErrString: p.Detail,
+		status:    status,
+	}, nil
+}
+
+// isProblemMime returns whether the given mime type is one of the Problem
+// Details mime types.
+func isProblemMime(mime string) bool {
+	return mime == ProblemJSONMime || mime == ProblemXMLMime
+}
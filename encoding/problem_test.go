@@ -0,0 +1,131 @@
+package encoding_test
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/ayiga/go-kit-middlewarer/encoding"
+)
+
+func problemContext(accept string) context.Context {
+	r, err := http.NewRequest("GET", "/not/important", nil)
+	if err != nil {
+		panic(err)
+	}
+	r.Header.Set("Accept", accept)
+	return encoding.PopulateRequestContext(context.Background(), r)
+}
+
+func TestErrorEncoderProblemJSON(t *testing.T) {
+	ctx := problemContext("application/json, application/problem+json")
+	rec := httptest.NewRecorder()
+	encoding.MakeErrorEncoder(encoding.Default())(ctx, NotFoundError{Resource: "foo"}, rec)
+
+	if got, want := rec.Header().Get("Content-Type"), encoding.ProblemJSONMime; got != want {
+		t.Fatalf("Content-Type:\ngot:\n\t%s\nwant:\n\t%s", got, want)
+	}
+
+	var members map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &members); err != nil {
+		t.Fatalf("Unable to Unmarshal Problem Details: %s", err)
+	}
+
+	want := map[string]interface{}{
+		"type":     "github.com/ayiga/go-kit-middlewarer/encoding_test.NotFoundError",
+		"title":    "Not Found",
+		"status":   float64(http.StatusNotFound),
+		"detail":   "foo was not found",
+		"resource": "foo",
+	}
+	if !reflect.DeepEqual(members, want) {
+		t.Errorf("Problem Details:\ngot:\n\t%v\nwant:\n\t%v", members, want)
+	}
+
+	resp := new(request)
+	r, err := encoding.Default().DecodeResponse(resp)(ctx, rec.Result())
+	if err != nil {
+		t.Fatalf("Unable to Decode Response: %s", err)
+	}
+
+	if got, want := r, (NotFoundError{Resource: "foo"}); !reflect.DeepEqual(got, want) {
+		t.Errorf("Error:\ngot:\n\t%#v\nwant:\n\t%#v", got, want)
+	}
+}
+
+func TestErrorEncoderProblemXML(t *testing.T) {
+	ctx := problemContext("application/problem+json;q=0.5, application/problem+xml")
+	rec := httptest.NewRecorder()
+	encoding.MakeErrorEncoder(encoding.Default())(ctx, NotFoundError{Resource: "foo"}, rec)
+
+	if got, want := rec.Header().Get("Content-Type"), encoding.ProblemXMLMime; got != want {
+		t.Fatalf("Content-Type:\ngot:\n\t%s\nwant:\n\t%s", got, want)
+	}
+
+	var p struct {
+		XMLName  xml.Name
+		Type     string `xml:"type"`
+		Status   int    `xml:"status"`
+		Resource string `xml:"Resource"`
+	}
+	if err := xml.Unmarshal(rec.Body.Bytes(), &p); err != nil {
+		t.Fatalf("Unable to Unmarshal Problem Details: %s", err)
+	}
+
+	if got, want := p.XMLName, (xml.Name{Space: "urn:ietf:rfc:7807", Local: "problem"}); got != want {
+		t.Errorf("XMLName:\ngot:\n\t%v\nwant:\n\t%v", got, want)
+	}
+
+	if got, want := p.Resource, "foo"; got != want {
+		t.Errorf("Resource:\ngot:\n\t%s\nwant:\n\t%s", got, want)
+	}
+
+	resp := new(request)
+	r, err := encoding.Default().DecodeResponse(resp)(ctx, rec.Result())
+	if err != nil {
+		t.Fatalf("Unable to Decode Response: %s", err)
+	}
+
+	if got, want := r, (NotFoundError{Resource: "foo"}); !reflect.DeepEqual(got, want) {
+		t.Errorf("Error:\ngot:\n\t%#v\nwant:\n\t%#v", got, want)
+	}
+}
+
+func TestErrorEncoderProblemWildcard(t *testing.T) {
+	ctx := problemContext("*/*")
+	rec := httptest.NewRecorder()
+	encoding.MakeErrorEncoder(encoding.Default())(ctx, NotFoundError{Resource: "foo"}, rec)
+
+	if got, want := rec.Header().Get("Content-Type"), "application/json"; got != want {
+		t.Errorf("Content-Type:\ngot:\n\t%s\nwant:\n\t%s", got, want)
+	}
+}
+
+func TestDecodeProblemUnregisteredType(t *testing.T) {
+	ctx := problemContext(encoding.ProblemJSONMime)
+	rec := httptest.NewRecorder()
+	encoding.MakeErrorEncoder(encoding.Default())(ctx, RetryAfterError{}, rec)
+
+	resp := new(request)
+	r, err := encoding.Default().DecodeResponse(resp)(ctx, rec.Result())
+	if err != nil {
+		t.Fatalf("Unable to Decode Response: %s", err)
+	}
+
+	we, ok := r.(encoding.WrapperError)
+	if !ok {
+		t.Fatalf("Type Of:\ngot:\n\t%T\nwant:\n\t%T", r, encoding.WrapperError{})
+	}
+
+	if got, want := we.Error(), "try again later"; got != want {
+		t.Errorf("Error:\ngot:\n\t%s\nwant:\n\t%s", got, want)
+	}
+
+	if got, want := we.StatusCode(), http.StatusServiceUnavailable; got != want {
+		t.Errorf("StatusCode:\ngot:\n\t%d\nwant:\n\t%d", got, want)
+	}
+}
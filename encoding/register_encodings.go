@@ -1,6 +1,8 @@
 package encoding
 
 import (
+	"net/http"
+
 	httptransport "github.com/go-kit/kit/transport/http"
 )
 
@@ -27,15 +29,31 @@ const (
 	// ErrNotImplemented represents that the functionality of this method is
 	// not implemented.
 	ErrNotImplemented
+	// ErrUnsupportedContentEncoding represents a content-coding that has no
+	// associated Compressor
+	ErrUnsupportedContentEncoding
+	// ErrNotAcceptable represents that none of the registered mime types are
+	// acceptable to the client, as indicated by the Accept header.
+	ErrNotAcceptable
+	// ErrRequestEntityTooLarge represents a Request body that exceeds the
+	// configured MaxBodySize.
+	ErrRequestEntityTooLarge
+	// ErrMaxDepthExceeded represents a Request body that nests deeper than the
+	// configured MaxJSONDepth.
+	ErrMaxDepthExceeded
 )
 
 var errToString = map[Err]string{
-	ErrAlreadyRegistered:     "That mime type already has already been registered",
-	ErrMimeNotFound:          "That mime type does not have an associated Encoder/Decoder",
-	ErrNoRegistrationsExist:  "Nothing has been registered, nothing to use for encoding/decoding",
-	ErrMimeNotSpecified:      "No information was given to help determine the mime type",
-	ErrUnableToDetermineMime: "Fall back to automatically detect the mime type has failed",
-	ErrNotImplemented:        "This method is not implemented",
+	ErrAlreadyRegistered:          "That mime type already has already been registered",
+	ErrMimeNotFound:               "That mime type does not have an associated Encoder/Decoder",
+	ErrNoRegistrationsExist:       "Nothing has been registered, nothing to use for encoding/decoding",
+	ErrMimeNotSpecified:           "No information was given to help determine the mime type",
+	ErrUnableToDetermineMime:      "Fall back to automatically detect the mime type has failed",
+	ErrNotImplemented:             "This method is not implemented",
+	ErrUnsupportedContentEncoding: "That content-coding does not have an associated Compressor",
+	ErrNotAcceptable:              "None of the registered mime types are acceptable",
+	ErrRequestEntityTooLarge:      "The Request body exceeds the maximum allowed size",
+	ErrMaxDepthExceeded:           "The Request body exceeds the maximum allowed depth",
 }
 
 // Error implements the error interface
@@ -43,6 +61,23 @@ func (e Err) Error() string {
 	return errToString[e]
 }
 
+// StatusCode implements github.com/go-kit/kit/transport/http.StatusCoder, so
+// that the appropriate status is reported for negotiation failures.
+func (e Err) StatusCode() int {
+	switch e {
+	case ErrNotAcceptable:
+		return http.StatusNotAcceptable
+	case ErrUnsupportedContentEncoding:
+		return http.StatusUnsupportedMediaType
+	case ErrRequestEntityTooLarge:
+		return http.StatusRequestEntityTooLarge
+	case ErrMaxDepthExceeded:
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
 // RequestResponseEncoding represents a type that can be used to automatically
 // Encode and Decode on HTTP requests used by files generated with
 // go-kit-middlewarer
@@ -65,32 +100,14 @@ type RequestResponseEncoding interface {
 	DecodeResponse(response interface{}) httptransport.DecodeResponseFunc
 }
 
-var mimeToEncodings = map[string]RequestResponseEncoding{}
-var mimeToFirstRunes = map[string][]rune{}
-
 // Register will register the associated encoding with the given mime type
+// within the DefaultRegistry
 func Register(mime string, encoding RequestResponseEncoding, startHint []rune) error {
-	if mimeToEncodings[mime] != nil {
-		return ErrAlreadyRegistered
-	}
-
-	if startHint != nil {
-		mimeToFirstRunes[mime] = startHint
-	}
-
-	mimeToEncodings[mime] = encoding
-	return nil
+	return DefaultRegistry.Register(mime, encoding, startHint)
 }
 
-// Get will retrieve the encoding registered with the mime-type
+// Get will retrieve the encoding registered with the mime-type within the
+// DefaultRegistry
 func Get(mime string) (RequestResponseEncoding, error) {
-	if len(mimeToEncodings) == 0 {
-		return nil, ErrNoRegistrationsExist
-	}
-
-	if mimeToEncodings[mime] == nil {
-		return nil, ErrMimeNotFound
-	}
-
-	return mimeToEncodings[mime], nil
+	return DefaultRegistry.Get(mime)
 }
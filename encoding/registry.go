@@ -0,0 +1,173 @@
+package encoding
+
+import (
+	"strings"
+	"sync"
+)
+
+// Registry represents a set of encodings, registered by their mime type, and
+// compressions, registered by their content-coding name.  A Registry is safe
+// for concurrent use.
+type Registry struct {
+	mu sync.RWMutex
+
+	mimeToEncodings  map[string]RequestResponseEncoding
+	mimeToFirstRunes map[string][]rune
+	mimes            []string
+
+	compressions     map[string]Compressor
+	compressionNames []string
+}
+
+// NewRegistry returns an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		mimeToEncodings:  map[string]RequestResponseEncoding{},
+		mimeToFirstRunes: map[string][]rune{},
+		compressions:     map[string]Compressor{},
+	}
+}
+
+// DefaultRegistry is the Registry used by Default, and by the package level
+// Register and Get functions.  JSON, XML, and Gob, as well as gzip, brotli,
+// and zstd, are registered with it by default.
+var DefaultRegistry = NewRegistry()
+
+// Register will register the associated encoding with the given mime type
+func (reg *Registry) Register(mime string, encoding RequestResponseEncoding, startHint []rune) error {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+
+	if reg.mimeToEncodings[mime] != nil {
+		return ErrAlreadyRegistered
+	}
+
+	if startHint != nil {
+		reg.mimeToFirstRunes[mime] = startHint
+	}
+
+	reg.mimeToEncodings[mime] = encoding
+	reg.mimes = append(reg.mimes, mime)
+	return nil
+}
+
+// Get will retrieve the encoding registered with the mime-type.  If nothing
+// has been registered with the mime-type, but it has a structured syntax
+// suffix, such as +json, the encoding registered for that suffix within
+// StructuredSuffixes is returned instead.
+func (reg *Registry) Get(mime string) (RequestResponseEncoding, error) {
+	reg.mu.RLock()
+	defer reg.mu.RUnlock()
+
+	if len(reg.mimeToEncodings) == 0 {
+		return nil, ErrNoRegistrationsExist
+	}
+
+	if encoding := reg.mimeToEncodings[mime]; encoding != nil {
+		return encoding, nil
+	}
+
+	if base, ok := suffixMime(mime); ok && reg.mimeToEncodings[base] != nil {
+		return reg.mimeToEncodings[base], nil
+	}
+
+	return nil, ErrMimeNotFound
+}
+
+// Unregister will remove the encoding registered with the mime-type
+func (reg *Registry) Unregister(mime string) error {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+
+	if reg.mimeToEncodings[mime] == nil {
+		return ErrMimeNotFound
+	}
+
+	delete(reg.mimeToEncodings, mime)
+	delete(reg.mimeToFirstRunes, mime)
+	for i, m := range reg.mimes {
+		if m == mime {
+			reg.mimes = append(reg.mimes[:i:i], reg.mimes[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+// List returns all of the registered mime types, in the order they were
+// registered.
+func (reg *Registry) List() []string {
+	reg.mu.RLock()
+	defer reg.mu.RUnlock()
+
+	return append([]string{}, reg.mimes...)
+}
+
+// firstRunes returns the start hint registered with the mime-type
+func (reg *Registry) firstRunes(mime string) []rune {
+	reg.mu.RLock()
+	defer reg.mu.RUnlock()
+
+	return reg.mimeToFirstRunes[mime]
+}
+
+// RegisterCompression will register the given Compressor with the given
+// content-coding name.  The order of registration is used as the preference
+// when a client expresses no preference between content-codings.
+func (reg *Registry) RegisterCompression(name string, c Compressor) error {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+
+	name = strings.ToLower(name)
+	if reg.compressions[name] != nil {
+		return ErrAlreadyRegistered
+	}
+
+	reg.compressions[name] = c
+	reg.compressionNames = append(reg.compressionNames, name)
+	return nil
+}
+
+// GetCompression will retrieve the Compressor registered with the given
+// content-coding name.
+func (reg *Registry) GetCompression(name string) (Compressor, error) {
+	reg.mu.RLock()
+	defer reg.mu.RUnlock()
+
+	c := reg.compressions[strings.ToLower(strings.TrimSpace(name))]
+	if c == nil {
+		return nil, ErrUnsupportedContentEncoding
+	}
+
+	return c, nil
+}
+
+// UnregisterCompression will remove the Compressor registered with the given
+// content-coding name.
+func (reg *Registry) UnregisterCompression(name string) error {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+
+	name = strings.ToLower(name)
+	if reg.compressions[name] == nil {
+		return ErrUnsupportedContentEncoding
+	}
+
+	delete(reg.compressions, name)
+	for i, n := range reg.compressionNames {
+		if n == name {
+			reg.compressionNames = append(reg.compressionNames[:i:i], reg.compressionNames[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+// ListCompressions returns all of the registered content-coding names, in the
+// order they were registered.
+func (reg *Registry) ListCompressions() []string {
+	reg.mu.RLock()
+	defer reg.mu.RUnlock()
+
+	return append([]string{}, reg.compressionNames...)
+}
@@ -0,0 +1,104 @@
+package encoding_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/ayiga/go-kit-middlewarer/encoding"
+)
+
+func TestRegistryRegisterGetUnregister(t *testing.T) {
+	reg := encoding.NewRegistry()
+
+	if _, err := reg.Get("application/json"); err != encoding.ErrNoRegistrationsExist {
+		t.Errorf("Get on empty Registry:\ngot:\n\t%v\nwant:\n\t%v", err, encoding.ErrNoRegistrationsExist)
+	}
+
+	if err := reg.Register("application/json", encoding.JSON(0), nil); err != nil {
+		t.Fatalf("Unable to Register: %s", err)
+	}
+
+	if err := reg.Register("application/xml", encoding.XML(0), []rune{'<'}); err != nil {
+		t.Fatalf("Unable to Register: %s", err)
+	}
+
+	if err := reg.Register("application/json", encoding.JSON(0), nil); err != encoding.ErrAlreadyRegistered {
+		t.Errorf("Duplicate Register:\ngot:\n\t%v\nwant:\n\t%v", err, encoding.ErrAlreadyRegistered)
+	}
+
+	if got, want := reg.List(), []string{"application/json", "application/xml"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("List:\ngot:\n\t%v\nwant:\n\t%v", got, want)
+	}
+
+	if err := reg.Unregister("application/json"); err != nil {
+		t.Fatalf("Unable to Unregister: %s", err)
+	}
+
+	if _, err := reg.Get("application/json"); err != encoding.ErrMimeNotFound {
+		t.Errorf("Get after Unregister:\ngot:\n\t%v\nwant:\n\t%v", err, encoding.ErrMimeNotFound)
+	}
+
+	if err := reg.Unregister("application/json"); err != encoding.ErrMimeNotFound {
+		t.Errorf("Duplicate Unregister:\ngot:\n\t%v\nwant:\n\t%v", err, encoding.ErrMimeNotFound)
+	}
+
+	if got, want := reg.List(), []string{"application/xml"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("List:\ngot:\n\t%v\nwant:\n\t%v", got, want)
+	}
+}
+
+func TestRegistryConcurrentUse(t *testing.T) {
+	reg := encoding.NewRegistry()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			mime := fmt.Sprintf("application/vnd.test%d+json", i)
+			reg.Register(mime, encoding.JSON(0), nil)
+			reg.Get(mime)
+			reg.List()
+			if i%2 == 0 {
+				reg.Unregister(mime)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if got, want := len(reg.List()), 25; got != want {
+		t.Errorf("len(List()):\ngot:\n\t%d\nwant:\n\t%d", got, want)
+	}
+}
+
+func TestNewWithRegistry(t *testing.T) {
+	reg := encoding.NewRegistry()
+	reg.Register("application/xml", encoding.XML(0), []rune{'<'})
+
+	r, err := http.NewRequest("GET", "/not/important", bytes.NewBufferString("<request><str>foo</str></request>"))
+	if err != nil {
+		panic(err)
+	}
+	r.Header.Set("Accept", "application/json, application/xml;q=0.1")
+
+	req := new(request)
+	req.embedMime = new(embedMime)
+	if _, err := encoding.New(reg).DecodeRequest(req)(context.Background(), r); err != nil {
+		t.Fatalf("Unable to Decode Request: %s", err)
+	}
+
+	if got, want := req.Str, "foo"; got != want {
+		t.Errorf("req.Str:\ngot:\n\t%s\nwant:\n\t%s", got, want)
+	}
+
+	// application/json is not a part of this Registry, so it should not be
+	// negotiated, even though the client prefers it.
+	if got, want := req.GetMime(), "application/xml"; got != want {
+		t.Errorf("mime:\ngot:\n\t%s\nwant:\n\t%s", got, want)
+	}
+}
@@ -0,0 +1,41 @@
+package encoding
+
+// ResponseError carries an error the server responded with as the error of
+// an endpoint.  The generated HTTP clients decode these errors as the
+// response, rather than the error, of their endpoints, so middlewares which
+// only consider errors, such as circuit breakers and retries, would
+// otherwise never see them.
+type ResponseError struct {
+	Err error
+}
+
+func (e ResponseError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap allows errors.Is and errors.As to match the error the server
+// responded with.
+func (e ResponseError) Unwrap() error {
+	return e.Err
+}
+
+// ResponseAsError returns an error the server responded with, as the
+// response, as a ResponseError instead.  Any other response and error are
+// returned as they are.
+func ResponseAsError(response interface{}, err error) (interface{}, error) {
+	if e, ok := response.(error); ok && err == nil {
+		return nil, ResponseError{e}
+	}
+
+	return response, err
+}
+
+// ErrorAsResponse reverses ResponseAsError, returning the error held by a
+// ResponseError as the response once more.
+func ErrorAsResponse(response interface{}, err error) (interface{}, error) {
+	if re, ok := err.(ResponseError); ok {
+		return re.Err, nil
+	}
+
+	return response, err
+}
@@ -0,0 +1,47 @@
+package encoding_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ayiga/go-kit-middlewarer/encoding"
+)
+
+func TestResponseAsError(t *testing.T) {
+	ie := encoding.InternalError{Path: "/uppercase", Message: "panic recovered"}
+
+	response, err := encoding.ResponseAsError(ie, nil)
+	if response != nil {
+		t.Errorf("Response:\ngot:\n\t%#v\nwant:\n\tnil", response)
+	}
+
+	var got encoding.InternalError
+	if !errors.As(err, &got) || got != ie {
+		t.Errorf("Error:\ngot:\n\t%#v\nwant:\n\t%#v", err, ie)
+	}
+
+	response, err = encoding.ErrorAsResponse(response, err)
+	if err != nil {
+		t.Errorf("Error:\ngot:\n\t%#v\nwant:\n\tnil", err)
+	}
+
+	if response != interface{}(ie) {
+		t.Errorf("Response:\ngot:\n\t%#v\nwant:\n\t%#v", response, ie)
+	}
+}
+
+func TestResponseAsErrorPassesThrough(t *testing.T) {
+	failure := errors.New("unreachable")
+
+	if response, err := encoding.ResponseAsError("upper", nil); response != "upper" || err != nil {
+		t.Errorf("ResponseAsError:\ngot:\n\t%#v, %v\nwant:\n\t%#v, <nil>", response, err, "upper")
+	}
+
+	if response, err := encoding.ResponseAsError(nil, failure); response != nil || err != failure {
+		t.Errorf("ResponseAsError:\ngot:\n\t%#v, %v\nwant:\n\t<nil>, %v", response, err, failure)
+	}
+
+	if response, err := encoding.ErrorAsResponse(nil, failure); response != nil || err != failure {
+		t.Errorf("ErrorAsResponse:\ngot:\n\t%#v, %v\nwant:\n\t<nil>, %v", response, err, failure)
+	}
+}
@@ -3,8 +3,10 @@ package encoding_test
 import (
 	"bytes"
 	"context"
+	"io"
 	"io/ioutil"
 	"net/http"
+	"strings"
 
 	"github.com/ayiga/go-kit-middlewarer/encoding"
 
@@ -316,3 +318,84 @@ func TestGobResponseSniff1(t *testing.T) {
 		t.Fail()
 	}
 }
+
+// chunkedReader hides the underlying type of its Reader, so that
+// net/http.NewRequest is unable to determine the ContentLength.
+type chunkedReader struct {
+	io.Reader
+}
+
+func TestJSONRequestSniffChunked(t *testing.T) {
+	var e request
+	e.embedMime = new(embedMime)
+	ctx := context.Background()
+
+	str := "\xef\xbb\xbf \r\n\t{\"str\":\"bar\",\"num\": 10,\"bool\":true,\"null\":null}"
+	request, err := http.NewRequest("GET", "/test", chunkedReader{strings.NewReader(str)})
+	if err != nil {
+		panic(err)
+	}
+
+	if got, want := request.ContentLength, int64(0); got != want {
+		t.Fatalf("request.ContentLength:\ngot:\n\t%d\nwant:\n\t%d", got, want)
+	}
+
+	if _, err := encoding.Default().DecodeRequest(&e)(ctx, request); err != nil {
+		t.Fatalf("Decode Request Failed: %s", err)
+	}
+
+	if got, want := e.Str, "bar"; got != want {
+		t.Errorf("e.Str:\ngot:\n\t%s\nwant:\n\t%s", got, want)
+	}
+
+	if got, want := e.GetMime(), "application/json"; got != want {
+		t.Errorf("e.GetMime():\ngot:\n\t%s\nwant:\n\t%s", got, want)
+	}
+}
+
+func TestXMLResponseSniffChunked(t *testing.T) {
+	var e request
+	e.embedMime = new(embedMime)
+	ctx := context.Background()
+
+	response := new(http.Response)
+	response.StatusCode = 200
+	response.ContentLength = -1
+	response.Body = ioutil.NopCloser(chunkedReader{strings.NewReader("\n  <request><str>bar</str><num>10.0</num></request>")})
+
+	if _, err := encoding.Default().DecodeResponse(&e)(ctx, response); err != nil {
+		t.Fatalf("Decode Response Failed: %s", err)
+	}
+
+	if got, want := e.Str, "bar"; got != want {
+		t.Errorf("e.Str:\ngot:\n\t%s\nwant:\n\t%s", got, want)
+	}
+
+	if got, want := e.GetMime(), "text/xml"; got != want {
+		t.Errorf("e.GetMime():\ngot:\n\t%s\nwant:\n\t%s", got, want)
+	}
+}
+
+func TestEmptySniff(t *testing.T) {
+	var e request
+	e.embedMime = new(embedMime)
+	ctx := context.Background()
+
+	request, err := http.NewRequest("GET", "/test", chunkedReader{strings.NewReader("  ")})
+	if err != nil {
+		panic(err)
+	}
+
+	if _, err := encoding.Default().DecodeRequest(&e)(ctx, request); err != encoding.ErrEmptyRequest {
+		t.Errorf("Decode Request:\ngot:\n\t%v\nwant:\n\t%v", err, encoding.ErrEmptyRequest)
+	}
+
+	response := new(http.Response)
+	response.StatusCode = 200
+	response.ContentLength = -1
+	response.Body = ioutil.NopCloser(chunkedReader{strings.NewReader("")})
+
+	if _, err := encoding.Default().DecodeResponse(&e)(ctx, response); err != encoding.ErrEmptyResponse {
+		t.Errorf("Decode Response:\ngot:\n\t%v\nwant:\n\t%v", err, encoding.ErrEmptyResponse)
+	}
+}
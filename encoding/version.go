@@ -0,0 +1,59 @@
+package encoding
+
+import (
+	"mime"
+	"regexp"
+	"strings"
+)
+
+// StructuredSuffixes maps the structured syntax suffixes of mime types, as
+// described by RFC 6839, to the mime type of the encoding that is used for
+// them when they have not been registered themselves.  This allows vendor
+// mime types, such as application/vnd.myco.stringsvc.v2+json, to be used
+// without registering each of them.
+var StructuredSuffixes = map[string]string{
+	"json": "application/json",
+	"xml":  "application/xml",
+}
+
+var mimeVersionExpr = regexp.MustCompile(`^v[0-9]+$`)
+
+// MimeVersion returns the version of the given vendor mime type, such as v2
+// for application/vnd.myco.stringsvc.v2+json.  If the mime type carries no
+// version, an empty string is returned.
+func MimeVersion(contentType string) string {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return ""
+	}
+
+	pieces := strings.SplitN(mediaType, "/", 2)
+	if len(pieces) != 2 || !strings.HasPrefix(pieces[1], "vnd.") {
+		return ""
+	}
+
+	subtype := pieces[1]
+	if i := strings.LastIndex(subtype, "+"); i >= 0 {
+		subtype = subtype[:i]
+	}
+
+	version := subtype[strings.LastIndex(subtype, ".")+1:]
+	if !mimeVersionExpr.MatchString(version) {
+		return ""
+	}
+
+	return version
+}
+
+// suffixMime returns the mime type registered for the structured syntax
+// suffix of the given mime type, if it has one.  Problem Details are excluded,
+// as they are only used to represent errors.
+func suffixMime(mime string) (string, bool) {
+	i := strings.LastIndex(mime, "+")
+	if i < 0 || isProblemMime(mime) {
+		return "", false
+	}
+
+	base, ok := StructuredSuffixes[mime[i+1:]]
+	return base, ok
+}
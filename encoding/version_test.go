@@ -0,0 +1,80 @@
+package encoding_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/ayiga/go-kit-middlewarer/encoding"
+)
+
+func TestMimeVersion(t *testing.T) {
+	tests := []struct {
+		mime string
+		want string
+	}{
+		{"application/vnd.myco.stringsvc.v2+json", "v2"},
+		{"application/vnd.myco.stringsvc.v10+xml; charset=utf-8", "v10"},
+		{"application/vnd.myco.stringsvc.v1", "v1"},
+		{"application/vnd.myco.stringsvc+json", ""},
+		{"application/vnd.myco.stringsvc.vnext+json", ""},
+		{"application/json", ""},
+		{"", ""},
+	}
+
+	for _, test := range tests {
+		if got := encoding.MimeVersion(test.mime); got != test.want {
+			t.Errorf("MimeVersion(%q):\ngot:\n\t%q\nwant:\n\t%q", test.mime, got, test.want)
+		}
+	}
+}
+
+func TestRegistryStructuredSuffixFallback(t *testing.T) {
+	reg := encoding.NewRegistry()
+	reg.Register("application/json", encoding.JSON(0), nil)
+
+	if enc, err := reg.Get("application/vnd.myco.stringsvc.v2+json"); err != nil || enc != encoding.JSON(0) {
+		t.Errorf("Get(+json):\ngot:\n\t%v, %v\nwant:\n\t%v, %v", enc, err, encoding.JSON(0), nil)
+	}
+
+	for _, mime := range []string{"application/vnd.myco.stringsvc.v2+xml", encoding.ProblemJSONMime} {
+		if _, err := reg.Get(mime); err != encoding.ErrMimeNotFound {
+			t.Errorf("Get(%q):\ngot:\n\t%v\nwant:\n\t%v", mime, err, encoding.ErrMimeNotFound)
+		}
+	}
+}
+
+func TestDecodeRequestVendorMime(t *testing.T) {
+	const mime = "application/vnd.myco.stringsvc.v1+json"
+	r, err := http.NewRequest("POST", "/not/important", bytes.NewBufferString("{\"str\":\"foo\"}"))
+	if err != nil {
+		panic(err)
+	}
+	r.Header.Set("Content-Type", mime)
+
+	req := new(request)
+	req.embedMime = new(embedMime)
+	if _, err := encoding.Default().DecodeRequest(req)(context.Background(), r); err != nil {
+		t.Fatalf("Unable to Decode Request: %s", err)
+	}
+
+	if got, want := req.Str, "foo"; got != want {
+		t.Errorf("req.Str:\ngot:\n\t%s\nwant:\n\t%s", got, want)
+	}
+
+	// the response should be in kind, so the client receives its version.
+	if got, want := req.GetMime(), mime; got != want {
+		t.Errorf("mime:\ngot:\n\t%s\nwant:\n\t%s", got, want)
+	}
+
+	buf := new(bytes.Buffer)
+	rw := createResponseWriter(buf)
+	if err := encoding.Default().EncodeResponse()(context.Background(), rw, req); err != nil {
+		t.Fatalf("Unable to Encode Response: %s", err)
+	}
+
+	if got, want := rw.Header().Get("Content-Type"), mime; got != want {
+		t.Errorf("Content-Type:\ngot:\n\t%s\nwant:\n\t%s", got, want)
+	}
+}
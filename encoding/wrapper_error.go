@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"encoding/xml"
 	"errors"
+	"net/http"
 	"reflect"
 	"strings"
 )
@@ -18,6 +19,27 @@ type WrapperError struct {
 	Type      string      `json:"type" xml:"type"`
 	ErrString string      `json:"errorString" xml:"error-string"`
 	Err       interface{} `json:"error" xml:"error"`
+
+	// Code identifies the error as a sentinel registered with
+	// RegisterSentinel.
+	Code string `json:"code,omitempty" xml:"code,omitempty"`
+
+	// Wrapped represents the errors returned by the Unwrap method of the
+	// error, if it has one.
+	Wrapped []WrapperError `json:"wrapped,omitempty" xml:"wrapped,omitempty"`
+
+	// status is the HTTP status code the WrapperError was received with.
+	status int
+}
+
+// StatusCode implements github.com/go-kit/kit/transport/http.StatusCoder, so
+// that the status code received is preserved if the error is passed along.
+func (we WrapperError) StatusCode() int {
+	if we.status == 0 {
+		return http.StatusInternalServerError
+	}
+
+	return we.status
 }
 
 func (we WrapperError) Error() string {
@@ -78,6 +100,10 @@ func (we *WrapperError) UnmarshalJSON(p []byte) error {
 				if we.Err != nil {
 					we.Err = reflect.Indirect(reflect.ValueOf(we.Err)).Interface()
 				}
+			case tagName(getTag("Code")):
+				err = dec.Decode(&we.Code)
+			case tagName(getTag("Wrapped")):
+				err = dec.Decode(&we.Wrapped)
 			default:
 				continue
 			}
@@ -131,6 +157,12 @@ func (we *WrapperError) UnmarshalXML(d *xml.Decoder, start xml.StartElement) err
 			if we.Err != nil {
 				we.Err = reflect.Indirect(reflect.ValueOf(we.Err)).Interface()
 			}
+		case tagName(getTag("Code")):
+			err = d.DecodeElement(&we.Code, &startToken)
+		case tagName(getTag("Wrapped")):
+			var wrapped WrapperError
+			err = d.DecodeElement(&wrapped, &startToken)
+			we.Wrapped = append(we.Wrapped, wrapped)
 		default:
 		}
 
@@ -142,34 +174,97 @@ func (we *WrapperError) UnmarshalXML(d *xml.Decoder, start xml.StartElement) err
 	return nil
 }
 
+// WrapError will wrap the given error, and every error within its Unwrap
+// chain, so that it may be transmitted.
 func WrapError(e error) *WrapperError {
 	t := reflect.TypeOf(e)
-	if _, err := GetErrorInstance(t.String()); err != nil {
+	we := &WrapperError{
+		Type:      errorTypeName(t),
+		ErrString: e.Error(),
+		Code:      sentinelCode(e),
+	}
+
+	if isRegisteredError(t) {
 		// don't transmit errors.errorString types
-		return &WrapperError{
-			Type:      t.String(),
-			ErrString: e.Error(),
-		}
+		we.Err = e
 	}
 
-	return &WrapperError{
-		Type:      t.String(),
-		ErrString: e.Error(),
-		Err:       e,
+	for _, w := range unwrapErrors(e) {
+		we.Wrapped = append(we.Wrapped, *WrapError(w))
 	}
+
+	return we
 }
 
 var ErrBlacklisted = errors.New("This Error type isn't able to registered, as it is not encodable / decodable")
 var ErrDuplicate = errors.New("You tried to register a duplicate type")
 var ErrUnknownError = errors.New("The type specified hasn't be registered")
 
+// registeredErrors holds the registered types by their qualified names, as
+// well as by their aliases.
 var registeredErrors = make(map[string]reflect.Type)
 
+// registeredShortNames holds the registered types by their
+// reflect.Type.String(), which is what older versions transmitted as their
+// type.  Names shared by more than one type are ambiguous, and are stored as
+// nil.
+var registeredShortNames = make(map[string]reflect.Type)
+
+// errorTypeAliases holds the names registered types are transmitted with, if
+// they have been given one via RegisterErrorAlias.
+var errorTypeAliases = make(map[reflect.Type]string)
+
+// qualifiedTypeName returns the name of the given type, qualified by the full
+// path of its package, rather than just its package name.
+func qualifiedTypeName(t reflect.Type) string {
+	if t.Kind() == reflect.Ptr {
+		return "*" + qualifiedTypeName(t.Elem())
+	}
+
+	if t.Name() == "" || t.PkgPath() == "" {
+		// builtin, or unnamed, types have nothing more to qualify them with.
+		return t.String()
+	}
+
+	return t.PkgPath() + "." + t.Name()
+}
+
+// errorTypeName returns the name the given error type is transmitted with.
+func errorTypeName(t reflect.Type) string {
+	if t.Kind() == reflect.Ptr {
+		return "*" + errorTypeName(t.Elem())
+	}
+
+	if alias, ok := errorTypeAliases[t]; ok {
+		return alias
+	}
+
+	return qualifiedTypeName(t)
+}
+
+// isRegisteredError returns whether the given type, or the type it points
+// to, has been registered with RegisterError.
+func isRegisteredError(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	return registeredErrors[qualifiedTypeName(t)] == t
+}
+
 // RegisterError will attempt to register the given Error with the encoders /
 // decoders and will make it available for Decoding errors for the encoders /
 // decoders.
 //
+// The type is registered by its name qualified with the full path of its
+// package, such as github.com/user/svc.NotFound.  Errors transmitted with only
+// their package name, such as svc.NotFound, are still understood, as long as
+// the name is not shared by another registered type.
+//
 // This will not automatically register this error type with encoding/gob
+//
+// Errors created by errors.New all share the same type, and so are unable to
+// be registered here.  Register them with RegisterSentinel instead.
 func RegisterError(e error) error {
 	t := reflect.TypeOf(e)
 	if reflect.TypeOf(ErrBlacklisted) == t {
@@ -178,15 +273,57 @@ func RegisterError(e error) error {
 
 	// ensure that we do not have a pointer
 	if t.Kind() == reflect.Ptr {
-		t = reflect.ValueOf(e).Type()
+		t = t.Elem()
 	}
 
-	if registeredErrors[t.String()] != nil {
+	name := qualifiedTypeName(t)
+	if registeredErrors[name] != nil {
 		return ErrDuplicate
 	}
 
 	// store the type information
-	registeredErrors[t.String()] = t
+	registeredErrors[name] = t
+	if other, ok := registeredShortNames[t.String()]; ok && other != t {
+		registeredShortNames[t.String()] = nil
+	} else {
+		registeredShortNames[t.String()] = t
+	}
+	return nil
+}
+
+// RegisterErrorAlias will register the type of the given error under the
+// given name, registering the type with RegisterError if it has not been
+// already.  The first alias registered for a type becomes the name it is
+// transmitted with, allowing the type to be renamed, or moved to another
+// package, without breaking existing clients.  Any further aliases are only
+// used when decoding, such as for the name of the type prior to a rename.
+func RegisterErrorAlias(alias string, e error) error {
+	t := reflect.TypeOf(e)
+	if reflect.TypeOf(ErrBlacklisted) == t {
+		return ErrBlacklisted
+	}
+
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if registered := registeredErrors[alias]; registered != nil {
+		if registered == t {
+			return nil
+		}
+		return ErrDuplicate
+	}
+
+	if !isRegisteredError(t) {
+		if err := RegisterError(e); err != nil {
+			return err
+		}
+	}
+
+	registeredErrors[alias] = t
+	if _, ok := errorTypeAliases[t]; !ok {
+		errorTypeAliases[t] = alias
+	}
 	return nil
 }
 
@@ -194,9 +331,15 @@ func RegisterError(e error) error {
 // of the given type string.  The error returned will be a pointer.
 func GetErrorInstance(s string) (interface{}, error) {
 	s = strings.TrimPrefix(s, "*")
-	if registeredErrors[s] == nil {
+	t := registeredErrors[s]
+	if t == nil {
+		// fall back to the names used by older versions
+		t = registeredShortNames[s]
+	}
+
+	if t == nil {
 		return nil, ErrUnknownError
 	}
 
-	return reflect.New(registeredErrors[s]).Interface(), nil
+	return reflect.New(t).Interface(), nil
 }
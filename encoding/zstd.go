@@ -0,0 +1,28 @@
+package encoding
+
+import (
+	"io"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+func init() {
+	RegisterCompression("zstd", Zstd(0))
+}
+
+// Zstd is a simple zstd compressor / decompressor that conforms to Compressor
+type Zstd int
+
+// Compress implements Compressor
+func (Zstd) Compress(w io.Writer) (io.WriteCloser, error) {
+	return zstd.NewWriter(w)
+}
+
+// Decompress implements Compressor
+func (Zstd) Decompress(r io.Reader) (io.ReadCloser, error) {
+	dec, err := zstd.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	return dec.IOReadCloser(), nil
+}
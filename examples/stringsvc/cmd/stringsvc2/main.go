@@ -6,8 +6,6 @@ import (
 	"net/http"
 	"os"
 
-	"golang.org/x/net/context"
-
 	httptrans "github.com/go-kit/kit/transport/http"
 
 	"github.com/ayiga/go-kit-middlewarer/encoding"
@@ -42,10 +40,8 @@ func main() {
 	switch mode {
 	case "server":
 		var svc StringService
-		options := []httptrans.ServerOption{httptrans.ServerErrorEncoder(func(ctx context.Context, err error, w http.ResponseWriter) {
-			w.WriteHeader(500)
-			encoding.JSON(1).EncodeResponse()(ctx, w, err)
-		}),
+		options := []httptrans.ServerOption{
+			httptrans.ServerErrorEncoder(encoding.MakeErrorEncoder(encoding.JSON(1))),
 		}
 		trans.ServersForEndpointsWithOptions(svc, []trans.ServerLayer{}, options)
 		http.ListenAndServe(args.httpPort, nil)
@@ -99,6 +99,7 @@ func (f *File) genImportsAndTypes(node ast.Node) bool {
 			name := t.Names[i].Name
 			// value := t.Values[i]
 			v := createVariable(name, "", typ)
+			v.isError = f.pkg.implementsError(t.Names[i])
 			f.pkg.variables = append(f.pkg.variables, v)
 			f.variables = append(f.variables, v)
 		}
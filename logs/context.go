@@ -0,0 +1,34 @@
+package logs
+
+import "context"
+
+type contextKey int
+
+const (
+	contextKeyTraceID contextKey = iota
+	contextKeyRequestID
+)
+
+// WithTraceID returns a copy of ctx holding the ID of the trace the request
+// is a part of, to be logged by the generated logging and slog middlewares.
+func WithTraceID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKeyTraceID, id)
+}
+
+// TraceID returns the trace ID held by ctx, if any.
+func TraceID(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(contextKeyTraceID).(string)
+	return id, ok && id != ""
+}
+
+// WithRequestID returns a copy of ctx holding the ID of the request, to be
+// logged by the generated logging and slog middlewares.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKeyRequestID, id)
+}
+
+// RequestID returns the request ID held by ctx, if any.
+func RequestID(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(contextKeyRequestID).(string)
+	return id, ok && id != ""
+}
@@ -0,0 +1,74 @@
+// Package logs implements the preparation of the values logged by the
+// generated logging and slog middlewares, and the request and trace IDs they
+// log, along with a log/slog backend for the logging middleware.
+//
+// Parameters and results holding secrets are redacted, and those too large
+// to be worth logging are omitted, when declared with a log annotation:
+//
+//	// @log redact password
+//	// @log omit blob
+package logs
+
+import (
+	"fmt"
+	"reflect"
+	"unicode/utf8"
+)
+
+// Redacted is logged in place of the values of redacted parameters and
+// results.
+const Redacted = "[REDACTED]"
+
+// DefaultMaxLength is the length, in bytes, values are truncated to when no
+// other maximum has been configured.
+const DefaultMaxLength = 256
+
+// Truncate returns the given value, unless its formatted representation is
+// longer than max bytes, in which case that representation is returned,
+// truncated to max bytes and followed by how many were left out.  Values are
+// never truncated if max is zero or less.
+func Truncate(v interface{}, max int) interface{} {
+	if max <= 0 || v == nil {
+		return v
+	}
+
+	var s string
+	switch t := v.(type) {
+	case string:
+		s = t
+	case []byte:
+		s = string(t)
+	case error, fmt.Stringer:
+		s = fmt.Sprint(t)
+	default:
+		switch reflect.ValueOf(v).Kind() {
+		case reflect.Struct, reflect.Map, reflect.Slice, reflect.Array, reflect.Ptr:
+			s = fmt.Sprintf("%+v", v)
+		default:
+			return v
+		}
+	}
+
+	if len(s) <= max {
+		return v
+	}
+
+	return TruncateString(s, max)
+}
+
+// TruncateString returns the given string, truncated to max bytes and
+// followed by how many were left out, if it's longer than max bytes.  Strings
+// are never truncated if max is zero or less.
+func TruncateString(s string, max int) string {
+	if max <= 0 || len(s) <= max {
+		return s
+	}
+
+	// avoid splitting a multi-byte rune
+	n := max
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+
+	return fmt.Sprintf("%s...(%d more bytes)", s[:n], len(s)-n)
+}
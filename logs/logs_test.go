@@ -0,0 +1,105 @@
+package logs_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-kit/kit/log/level"
+
+	"github.com/ayiga/go-kit-middlewarer/logs"
+)
+
+type person struct {
+	Name string
+}
+
+func TestTruncate(t *testing.T) {
+	long := strings.Repeat("a", 20)
+	p := person{Name: long}
+
+	cases := []struct {
+		name  string
+		value interface{}
+		max   int
+		want  interface{}
+	}{
+		{"short string", "abc", 10, "abc"},
+		{"long string", long, 10, "aaaaaaaaaa...(10 more bytes)"},
+		{"bytes", []byte(long), 10, "aaaaaaaaaa...(10 more bytes)"},
+		{"short bytes", []byte("abc"), 10, []byte("abc")},
+		{"error", errors.New(long), 15, "aaaaaaaaaaaaaaa...(5 more bytes)"},
+		{"short struct", person{Name: "a"}, 20, person{Name: "a"}},
+		{"long struct", p, 10, "{Name:aaaa...(17 more bytes)"},
+		{"number", 1234567890, 2, 1234567890},
+		{"multi-byte rune", "héllo", 2, "h...(5 more bytes)"},
+		{"disabled", long, 0, long},
+		{"nil", nil, 10, nil},
+	}
+
+	for _, c := range cases {
+		if got := logs.Truncate(c.value, c.max); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("%s:\ngot:\n\t%#v\nwant:\n\t%#v", c.name, got, c.want)
+		}
+	}
+}
+
+func TestSlog(t *testing.T) {
+	var buf bytes.Buffer
+	logger := logs.Slog(slog.New(slog.NewTextHandler(&buf, nil)))
+
+	if err := level.Error(logger).Log("method", "/uppercase", "err", "boom"); err != nil {
+		t.Fatalf("Log: %s", err)
+	}
+	if err := level.Debug(logger).Log("method", "/count"); err != nil {
+		t.Fatalf("Log: %s", err)
+	}
+	if err := logger.Log("msg", "hello", "odd"); err != nil {
+		t.Fatalf("Log: %s", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected the debug record to be dropped, got:\n%s", buf.String())
+	}
+
+	for i, want := range []string{
+		`level=ERROR msg="" method=/uppercase err=boom`,
+		`level=INFO msg=hello odd=(MISSING)`,
+	} {
+		if !strings.HasSuffix(lines[i], want) {
+			t.Errorf("line %d:\ngot:\n\t%s\nwant suffix:\n\t%s", i, lines[i], want)
+		}
+	}
+}
+
+func TestTruncateString(t *testing.T) {
+	if got, want := logs.TruncateString("abcdef", 3), "abc...(3 more bytes)"; got != want {
+		t.Errorf("TruncateString:\ngot:\n\t%q\nwant:\n\t%q", got, want)
+	}
+	if got, want := logs.TruncateString("abcdef", -1), "abcdef"; got != want {
+		t.Errorf("TruncateString:\ngot:\n\t%q\nwant:\n\t%q", got, want)
+	}
+}
+
+func TestContextIDs(t *testing.T) {
+	ctx := context.Background()
+	if _, ok := logs.TraceID(ctx); ok {
+		t.Errorf("TraceID: expected no trace ID")
+	}
+	if _, ok := logs.RequestID(logs.WithRequestID(ctx, "")); ok {
+		t.Errorf("RequestID: expected an empty request ID to be absent")
+	}
+
+	ctx = logs.WithRequestID(logs.WithTraceID(ctx, "trace"), "request")
+	if id, ok := logs.TraceID(ctx); !ok || id != "trace" {
+		t.Errorf("TraceID:\ngot:\n\t%q %t\nwant:\n\t%q %t", id, ok, "trace", true)
+	}
+	if id, ok := logs.RequestID(ctx); !ok || id != "request" {
+		t.Errorf("RequestID:\ngot:\n\t%q %t\nwant:\n\t%q %t", id, ok, "request", true)
+	}
+}
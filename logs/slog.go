@@ -0,0 +1,63 @@
+//go:build go1.21
+
+package logs
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+)
+
+// Slog returns a github.com/go-kit/kit/log.Logger that logs through the given
+// *slog.Logger, so the generated logging middleware may be used with it.  The
+// level set by github.com/go-kit/kit/log/level becomes the level of the
+// record, defaulting to slog.LevelInfo, and the msg key, if any, becomes its
+// message.  Every other key and value is added as an attribute.
+func Slog(l *slog.Logger) log.Logger {
+	return slogLogger{l}
+}
+
+type slogLogger struct {
+	l *slog.Logger
+}
+
+// Log implements github.com/go-kit/kit/log.Logger
+func (s slogLogger) Log(keyvals ...interface{}) error {
+	lvl := slog.LevelInfo
+	var msg string
+	attrs := make([]slog.Attr, 0, len(keyvals)/2)
+	for i := 0; i < len(keyvals); i += 2 {
+		var v interface{} = log.ErrMissingValue
+		if i+1 < len(keyvals) {
+			v = keyvals[i+1]
+		}
+
+		switch k := keyvals[i]; {
+		case k == level.Key():
+			lvl = slogLevel(v)
+		case k == "msg":
+			msg = fmt.Sprint(v)
+		default:
+			attrs = append(attrs, slog.Any(fmt.Sprint(k), v))
+		}
+	}
+
+	s.l.LogAttrs(context.Background(), lvl, msg, attrs...)
+	return nil
+}
+
+func slogLevel(v interface{}) slog.Level {
+	switch v {
+	case level.DebugValue():
+		return slog.LevelDebug
+	case level.WarnValue():
+		return slog.LevelWarn
+	case level.ErrorValue():
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
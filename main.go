@@ -10,7 +10,7 @@ import (
 
 var (
 	typeNames             = flag.String("type", "", "comma-separated list of type names; must be set")
-	middlewaresToGenerate = flag.String("middleware", "logging,instrumenting,transport,zipkin", "comma-seperated list of middlewares to process. Options: [logging,instrumenting,transport,zipkin]")
+	middlewaresToGenerate = flag.String("middleware", "logging,instrumenting,transport,zipkin", "comma-seperated list of middlewares to process. Options: [logging,instrumenting,transport,zipkin,validation,ratelimit,circuitbreaker,caching,auth,authorization,recovery,slog,otel,requestid]")
 	summarize             = flag.String("summarize", "", "Prints out the Summary of Found structures intead of generating code")
 	binaryName            = ""
 )
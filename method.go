@@ -3,7 +3,12 @@ package main
 import (
 	"fmt"
 	"go/ast"
+	"go/parser"
+	"regexp"
 	"strings"
+	"time"
+
+	"github.com/ayiga/go-kit-middlewarer/validate"
 )
 
 type Method struct {
@@ -17,6 +22,20 @@ type Method struct {
 	errorResultName   string
 	moreThanOneResult bool
 
+	annotations []Annotation
+	versions    []MethodVersion
+	validations []MethodValidation
+	idempotent  bool
+	hedged      bool
+	cached      bool
+	cacheTTL    time.Duration
+	auth        bool
+	authSchemes []string
+	scopes      []string
+	roles       []string
+	logRedact   []string
+	logOmit     []string
+
 	pkg        *Package
 	file       File
 	imports    []Import
@@ -95,9 +114,207 @@ func createMethod(field *ast.Field, reservedNames []string, file File) Method {
 		m.moreThanOneResult = numResult > 1
 	}
 
+	m.annotations = parseAnnotations(field.Doc)
+	for _, a := range findAnnotations(m.annotations, "version") {
+		m.versions = append(m.versions, createMethodVersion(m.name, a, names, file))
+	}
+	for _, a := range findAnnotations(m.annotations, "validate") {
+		m.validations = append(m.validations, createMethodValidation(m, a))
+	}
+	m.idempotent = len(findAnnotations(m.annotations, "idempotent")) > 0
+	m.hedged = len(findAnnotations(m.annotations, "hedge")) > 0
+	if cache := findAnnotations(m.annotations, "cache"); len(cache) > 0 {
+		m.cached = true
+		m.cacheTTL = parseCacheTTL(m, cache[0])
+	}
+	if auth := findAnnotations(m.annotations, "auth"); len(auth) > 0 {
+		m.auth = true
+		m.authSchemes = parseAuthSchemes(m, auth[0])
+	}
+	for _, a := range findAnnotations(m.annotations, "scope") {
+		m.scopes = append(m.scopes, strings.Fields(a.args)...)
+	}
+	for _, a := range findAnnotations(m.annotations, "role") {
+		m.roles = append(m.roles, strings.Fields(a.args)...)
+	}
+	for _, a := range findAnnotations(m.annotations, "log") {
+		action, names := parseLogAnnotation(m, a)
+		if action == "redact" {
+			m.logRedact = append(m.logRedact, names...)
+		} else {
+			m.logOmit = append(m.logOmit, names...)
+		}
+	}
+
 	return m
 }
 
+// MethodValidation represents the rules a parameter of a Method is validated
+// against, as declared by a validate annotation:
+//
+//	// @validate str required,max=256
+//
+// The rules are parsed by the validate package, so that malformed rules are
+// rejected when generating, rather than when the generated code is
+// initialized.
+type MethodValidation struct {
+	param string
+	rules string
+}
+
+func createMethodValidation(m Method, a Annotation) MethodValidation {
+	pieces := strings.SplitN(a.args, " ", 2)
+	if len(pieces) != 2 || strings.TrimSpace(pieces[1]) == "" {
+		log.Fatalf("%s: invalid validate annotation, expected @validate param rules: %s", m.name, a.args)
+	}
+
+	for _, p := range m.params {
+		if sliceContains(p.names, pieces[0]) && p.typ.String() != "context.Context" {
+			if _, err := validate.Parse(pieces[1]); err != nil {
+				log.Fatalf("%s: invalid validate annotation for %s: %s", m.name, pieces[0], err)
+			}
+
+			return MethodValidation{
+				param: pieces[0],
+				rules: strings.TrimSpace(pieces[1]),
+			}
+		}
+	}
+
+	log.Fatalf("%s: unable to validate %s, as it is not a parameter", m.name, pieces[0])
+	return MethodValidation{}
+}
+
+// parseCacheTTL returns how long the results of a Method are cached for, as
+// declared by a cache annotation, or zero if the default is to be used:
+//
+//	// @cache 30s
+func parseCacheTTL(m Method, a Annotation) time.Duration {
+	if a.args == "" {
+		return 0
+	}
+
+	ttl, err := time.ParseDuration(a.args)
+	if err != nil || ttl <= 0 {
+		log.Fatalf("%s: invalid cache annotation, expected @cache [ttl]: %s", m.name, a.args)
+	}
+
+	return ttl
+}
+
+// parseAuthSchemes returns the schemes a Method accepts credentials of, as
+// declared by an auth annotation, or none if it doesn't require credentials:
+//
+//	// @auth jwt apikey
+func parseAuthSchemes(m Method, a Annotation) []string {
+	schemes := strings.Fields(a.args)
+	if len(schemes) == 0 {
+		log.Fatalf("%s: invalid auth annotation, expected @auth scheme... or @auth none", m.name)
+	}
+
+	if sliceContains(schemes, "none") {
+		if len(schemes) > 1 {
+			log.Fatalf("%s: invalid auth annotation, none excludes every other scheme: %s", m.name, a.args)
+		}
+
+		return []string{}
+	}
+
+	return schemes
+}
+
+// parseLogAnnotation returns the action, and the names of the parameters and
+// results it applies to, declared by a log annotation.  Redacted values are
+// logged as a placeholder, while omitted values aren't logged at all:
+//
+//	// @log redact password
+//	// @log omit blob
+func parseLogAnnotation(m Method, a Annotation) (string, []string) {
+	fields := strings.Fields(a.args)
+	if len(fields) < 2 || (fields[0] != "redact" && fields[0] != "omit") {
+		log.Fatalf("%s: invalid log annotation, expected @log redact|omit name...: %s", m.name, a.args)
+	}
+
+	for _, n := range fields[1:] {
+		found := false
+		for _, p := range append(append([]Param{}, m.params...), m.results...) {
+			if sliceContains(p.names, n) && p.typ.String() != "context.Context" {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			log.Fatalf("%s: unable to %s %s, as it is not a parameter or result", m.name, fields[0], n)
+		}
+	}
+
+	return fields[0], fields[1:]
+}
+
+// MethodVersion represents a prior version of a Method's signature, as
+// declared by a version annotation:
+//
+//	// @version v1 (str string) (upper string, err error)
+//
+// Requests and Responses with a versioned mime type, such as
+// application/vnd.myco.stringsvc.v1+json, use these parameters and results.
+type MethodVersion struct {
+	name    string
+	params  []Param
+	results []Param
+}
+
+var versionNameExpr = regexp.MustCompile(`^v[0-9]+$`)
+
+func createMethodVersion(methodName string, a Annotation, reservedNames []string, file File) MethodVersion {
+	pieces := strings.SplitN(a.args, " ", 2)
+	if len(pieces) != 2 || !versionNameExpr.MatchString(pieces[0]) {
+		log.Fatalf("%s: invalid version annotation, expected @version vN (params) (results): %s", methodName, a.args)
+	}
+
+	expr, err := parser.ParseExpr("func" + pieces[1])
+	if err != nil {
+		log.Fatalf("%s: unable to parse the signature of version %s: %s", methodName, pieces[0], err)
+	}
+
+	fun, ok := expr.(*ast.FuncType)
+	if !ok {
+		log.Fatalf("%s: the signature of version %s is not a function signature", methodName, pieces[0])
+	}
+
+	v := MethodVersion{
+		name: pieces[0],
+	}
+
+	names := append([]string{}, reservedNames...)
+	if fun.Params != nil {
+		for _, f := range fun.Params.List {
+			param := createParam(f, names, "input", file)
+			v.params = append(v.params, param)
+			names = append(names, param.names...)
+		}
+	}
+
+	if fun.Results != nil {
+		for _, f := range fun.Results.List {
+			param := createParam(f, names, "output", file)
+			v.results = append(v.results, param)
+			names = append(names, param.names...)
+		}
+	}
+
+	for _, p := range append(append([]Param{}, v.params...), v.results...) {
+		for _, imp := range file.pkg.imports {
+			if strings.HasPrefix(p.typ.String(), fmt.Sprintf("%s.", imp.name)) {
+				imp.isParam = true
+			}
+		}
+	}
+
+	return v
+}
+
 func (m Method) usedNames() []string {
 	var result []string
 	result = append(result, m.name)
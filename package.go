@@ -37,6 +37,30 @@ func (pkg *Package) check(fs *token.FileSet, astFiles []*ast.File) {
 	pkg.typesPkg = typesPkg
 }
 
+// implementsError returns whether the value declared by the given identifier
+// implements the error interface.
+func (pkg *Package) implementsError(ident *ast.Ident) bool {
+	obj := pkg.defs[ident]
+	if obj == nil {
+		return false
+	}
+
+	errorType := types.Universe.Lookup("error").Type().Underlying().(*types.Interface)
+	return types.Implements(obj.Type(), errorType)
+}
+
+// sentinelErrors returns the names of the exported values within the package
+// that implement the error interface.
+func (pkg *Package) sentinelErrors() []string {
+	var names []string
+	for _, v := range pkg.variables {
+		if v.isError {
+			names = append(names, v.name)
+		}
+	}
+	return names
+}
+
 func (pkg *Package) Summarize() {
 	fmt.Println("Summary")
 	fmt.Printf("%s:\n", pkg.name)
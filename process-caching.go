@@ -0,0 +1,62 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"text/template"
+)
+
+func processCaching(g *Generator, f *File) {
+	gopath := os.Getenv("GOPATH")
+
+	var buf bytes.Buffer
+
+	tmpl, err := template.ParseFiles(filepath.Join(gopath, "src", "github.com", "ayiga", "go-kit-middlewarer", "tmpl", "caching.tmpl"))
+	if err != nil {
+		log.Fatalf("Template Parse Error: %s", err)
+	}
+
+	convertedPath := filepath.ToSlash(f.pkg.dir)
+
+	endpointPackage := createImportWithPath(path.Join(convertedPath, "endpoint"))
+	basePackage := createImportWithPath(convertedPath)
+
+	for _, interf := range f.interfaces {
+		for _, m := range interf.methods {
+			if m.cached && !hasCacheableResult(m) {
+				log.Fatalf("%s: unable to cache, as it has no results besides an error", m.name)
+			}
+		}
+
+		err := tmpl.Execute(&buf, createTemplateBase(basePackage, endpointPackage, interf, f.imports))
+		if err != nil {
+			log.Fatalf("Template execution failed: %s\n", err)
+		}
+	}
+
+	filename := "middleware_gen.go"
+
+	file := openFile(filepath.Join(".", "caching"), filename)
+	defer file.Close()
+
+	fmt.Fprint(file, string(formatBuffer(buf, filename)))
+}
+
+// hasCacheableResult reports whether the Method has a result that isn't an
+// error.
+func hasCacheableResult(m Method) bool {
+	for _, r := range m.results {
+		if r.typ.String() != "error" {
+			return true
+		}
+	}
+
+	return false
+}
+
+func init() {
+	registerProcess("caching", processCaching)
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"text/template"
+)
+
+func processCircuitBreaker(g *Generator, f *File) {
+	gopath := os.Getenv("GOPATH")
+
+	var buf bytes.Buffer
+
+	tmpl, err := template.ParseFiles(filepath.Join(gopath, "src", "github.com", "ayiga", "go-kit-middlewarer", "tmpl", "circuitbreaker.tmpl"))
+	if err != nil {
+		log.Fatalf("Template Parse Error: %s", err)
+	}
+
+	convertedPath := filepath.ToSlash(f.pkg.dir)
+
+	endpointPackage := createImportWithPath(path.Join(convertedPath, "endpoint"))
+	basePackage := createImportWithPath(convertedPath)
+
+	for _, interf := range f.interfaces {
+		err := tmpl.Execute(&buf, createTemplateBase(basePackage, endpointPackage, interf, f.imports))
+		if err != nil {
+			log.Fatalf("Template execution failed: %s\n", err)
+		}
+	}
+
+	filename := "middleware_gen.go"
+
+	file := openFile(filepath.Join(".", "circuitbreaker"), filename)
+	defer file.Close()
+
+	fmt.Fprint(file, string(formatBuffer(buf, filename)))
+}
+
+func init() {
+	registerProcess("circuitbreaker", processCircuitBreaker)
+}
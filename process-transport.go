@@ -144,6 +144,7 @@ func processTransport(g *Generator, f *File) {
 
 	for _, interf := range f.interfaces {
 		tb := createTemplateBase(basePackage, endpointPackage, interf, f.imports)
+		tb.Sentinels = f.pkg.sentinelErrors()
 		processRequestResponse(gopath, tb)
 		processMakeEndpoint(gopath, tb)
 		processHTTPServer(gopath, tb)
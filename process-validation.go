@@ -0,0 +1,83 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"text/template"
+
+	"github.com/ayiga/go-kit-middlewarer/validate"
+)
+
+func processValidation(g *Generator, f *File) {
+	gopath := os.Getenv("GOPATH")
+
+	var buf bytes.Buffer
+
+	tmpl, err := template.ParseFiles(filepath.Join(gopath, "src", "github.com", "ayiga", "go-kit-middlewarer", "tmpl", "validation.tmpl"))
+	if err != nil {
+		log.Fatalf("Template Parse Error: %s", err)
+	}
+
+	for _, stru := range f.pkg.structs {
+		checkValidateTags(stru.name, stru.typ)
+	}
+
+	convertedPath := filepath.ToSlash(f.pkg.dir)
+
+	endpointPackage := createImportWithPath(path.Join(convertedPath, "endpoint"))
+	basePackage := createImportWithPath(convertedPath)
+
+	for _, interf := range f.interfaces {
+		for _, m := range interf.methods {
+			if len(m.validations) > 0 && !m.hasErrResult {
+				log.Fatalf("%s: unable to validate, as it does not return an error", m.name)
+			}
+		}
+
+		err := tmpl.Execute(&buf, createTemplateBase(basePackage, endpointPackage, interf, f.imports))
+		if err != nil {
+			log.Fatalf("Template execution failed: %s\n", err)
+		}
+	}
+
+	filename := "middleware_gen.go"
+
+	file := openFile(filepath.Join(".", "validation"), filename)
+	defer file.Close()
+
+	fmt.Fprint(file, string(formatBuffer(buf, filename)))
+}
+
+// checkValidateTags rejects the malformed validate tags of a struct's fields,
+// and of the structs declared within it, which would otherwise only be found
+// when a request is validated.
+func checkValidateTags(name string, typ *ast.StructType) {
+	for _, field := range typ.Fields.List {
+		if inner, ok := field.Type.(*ast.StructType); ok {
+			checkValidateTags(name, inner)
+		}
+
+		if field.Tag == nil {
+			continue
+		}
+
+		tag, err := strconv.Unquote(field.Tag.Value)
+		if err != nil {
+			log.Fatalf("%s: unable to read struct tag %s: %s", name, field.Tag.Value, err)
+		}
+
+		if _, _, err := validate.ParseTag(reflect.StructTag(tag)); err != nil {
+			log.Fatalf("%s: %s: %s", name, field.Tag.Value, err)
+		}
+	}
+}
+
+func init() {
+	registerProcess("validation", processValidation)
+}
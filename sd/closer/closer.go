@@ -0,0 +1,55 @@
+// Package closer provides the io.Closer returned, along with the endpoint of
+// an address, by a github.com/go-kit/kit/sd.Factory, which cancels the calls
+// still in flight to the address once it has been removed by the
+// github.com/go-kit/kit/sd.Endpointer.
+package closer
+
+import (
+	"context"
+	"errors"
+
+	"github.com/go-kit/kit/endpoint"
+)
+
+// ErrClosed is returned by the endpoints of a Closer that has been closed.
+var ErrClosed = errors.New("the endpoint has been closed")
+
+// Closer cancels the calls made through its Middleware when closed.  Nothing
+// else is released, so that a net/http.Client shared by every address keeps
+// its connections to the others.
+type Closer struct {
+	ctx    context.Context
+	cancel context.CancelCauseFunc
+}
+
+// New returns a Closer that has yet to be closed.
+func New() *Closer {
+	ctx, cancel := context.WithCancelCause(context.Background())
+	return &Closer{ctx, cancel}
+}
+
+// Middleware cancels the context of the calls in flight when the Closer is
+// closed, with ErrClosed as the cause, and refuses calls made after.
+func (c *Closer) Middleware(next endpoint.Endpoint) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		if c.ctx.Err() != nil {
+			return nil, ErrClosed
+		}
+
+		ctx, cancel := context.WithCancelCause(ctx)
+		defer cancel(nil)
+
+		stop := context.AfterFunc(c.ctx, func() {
+			cancel(ErrClosed)
+		})
+		defer stop()
+
+		return next(ctx, request)
+	}
+}
+
+// Close cancels the calls in flight, and those made after.  It never fails.
+func (c *Closer) Close() error {
+	c.cancel(ErrClosed)
+	return nil
+}
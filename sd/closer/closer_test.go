@@ -0,0 +1,122 @@
+package closer_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/sd"
+
+	"github.com/ayiga/go-kit-middlewarer/sd/closer"
+)
+
+// blocked returns an endpoint that blocks until its call is cancelled, sending
+// the cause of the cancellation.
+func blocked(started chan<- struct{}, causes chan<- error) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		started <- struct{}{}
+		<-ctx.Done()
+		causes <- context.Cause(ctx)
+		return nil, ctx.Err()
+	}
+}
+
+func TestCloseCancelsCallsInFlight(t *testing.T) {
+	started, causes := make(chan struct{}), make(chan error, 1)
+	c := closer.New()
+	ep := c.Middleware(blocked(started, causes))
+
+	go ep(context.Background(), nil)
+	<-started
+
+	if err := c.Close(); err != nil {
+		t.Fatalf("Unable to close: %s", err)
+	}
+
+	select {
+	case cause := <-causes:
+		if cause != closer.ErrClosed {
+			t.Errorf("Cause:\ngot:\n\t%v\nwant:\n\t%v", cause, closer.ErrClosed)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("The call in flight was not cancelled")
+	}
+
+	if _, err := ep(context.Background(), nil); err != closer.ErrClosed {
+		t.Errorf("Error:\ngot:\n\t%v\nwant:\n\t%v", err, closer.ErrClosed)
+	}
+}
+
+func TestCloseLeavesOthers(t *testing.T) {
+	closed, open := closer.New(), closer.New()
+	closed.Close()
+
+	ep := open.Middleware(func(ctx context.Context, request interface{}) (interface{}, error) {
+		return "OK", ctx.Err()
+	})
+
+	if response, err := ep(context.Background(), nil); err != nil || response != "OK" {
+		t.Errorf("Response:\ngot:\n\t%#v %v\nwant:\n\t%#v <nil>", response, err, "OK")
+	}
+}
+
+// instancer yields the instances it's updated with.
+type instancer struct {
+	mu  sync.Mutex
+	chs []chan<- sd.Event
+}
+
+func (i *instancer) Register(ch chan<- sd.Event) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.chs = append(i.chs, ch)
+}
+
+func (i *instancer) Deregister(ch chan<- sd.Event) {}
+func (i *instancer) Stop()                         {}
+
+func (i *instancer) update(instances ...string) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	for _, ch := range i.chs {
+		ch <- sd.Event{Instances: instances}
+	}
+}
+
+func TestEndpointerClosesRemovedInstances(t *testing.T) {
+	started, causes := make(chan struct{}), make(chan error, 1)
+	factory := func(instance string) (endpoint.Endpoint, io.Closer, error) {
+		c := closer.New()
+		return c.Middleware(blocked(started, causes)), c, nil
+	}
+
+	var i instancer
+	endpointer := sd.NewEndpointer(&i, factory, log.NewNopLogger())
+	defer endpointer.Close()
+	i.update("a")
+
+	endpoints, err := endpointer.Endpoints()
+	if err != nil || len(endpoints) != 1 {
+		t.Fatalf("Endpoints:\ngot:\n\t%d %v\nwant:\n\t1 <nil>", len(endpoints), err)
+	}
+
+	go endpoints[0](context.Background(), nil)
+	<-started
+
+	// the instance is removed, so its call in flight is cancelled
+	i.update("b")
+
+	select {
+	case cause := <-causes:
+		if !errors.Is(cause, closer.ErrClosed) {
+			t.Errorf("Cause:\ngot:\n\t%v\nwant:\n\t%v", cause, closer.ErrClosed)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("The call in flight to the removed instance was not cancelled")
+	}
+}
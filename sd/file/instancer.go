@@ -0,0 +1,172 @@
+// Package file provides an github.com/go-kit/kit/sd.Instancer that reads its
+// instances from a JSON file, which is watched for changes.
+package file
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/sd"
+)
+
+// Instancer yields the instances listed within a JSON file, as an array of
+// addresses:
+//
+//	["10.0.0.1:8080", "10.0.0.2:8080"]
+//
+// The file is checked for changes periodically, by its modification time and
+// size, and the instances are re-read whenever it changes.  If the file is
+// unable to be read or parsed, the error is yielded instead, leaving it to
+// the github.com/go-kit/kit/sd.Endpointer to decide what to do with the
+// instances it already has.
+type Instancer struct {
+	path   string
+	logger log.Logger
+	quit   chan struct{}
+
+	mtx         sync.Mutex
+	state       sd.Event
+	modTime     time.Time
+	size        int64
+	subscribers map[chan<- sd.Event]struct{}
+}
+
+// NewInstancer returns an Instancer for the file at the given path, which is
+// checked for changes every interval.  The file is read before returning, so
+// that the instances are available immediately.
+func NewInstancer(path string, interval time.Duration, logger log.Logger) *Instancer {
+	in := &Instancer{
+		path:        path,
+		logger:      logger,
+		quit:        make(chan struct{}),
+		subscribers: make(map[chan<- sd.Event]struct{}),
+	}
+
+	in.check()
+	go in.loop(time.NewTicker(interval))
+	return in
+}
+
+func (in *Instancer) loop(t *time.Ticker) {
+	defer t.Stop()
+	for {
+		select {
+		case <-t.C:
+			in.check()
+		case <-in.quit:
+			return
+		}
+	}
+}
+
+// check reads the file, if it has changed since it was last read.
+func (in *Instancer) check() {
+	info, err := os.Stat(in.path)
+	if err != nil {
+		in.logger.Log("path", in.path, "err", err)
+		in.update(sd.Event{Err: err}, time.Time{}, 0)
+		return
+	}
+
+	in.mtx.Lock()
+	unchanged := in.state.Err == nil && info.ModTime().Equal(in.modTime) && info.Size() == in.size
+	in.mtx.Unlock()
+	if unchanged {
+		return
+	}
+
+	instances, err := read(in.path)
+	if err != nil {
+		in.logger.Log("path", in.path, "err", err)
+		in.update(sd.Event{Err: err}, time.Time{}, 0)
+		return
+	}
+
+	in.logger.Log("path", in.path, "instances", len(instances))
+	in.update(sd.Event{Instances: instances}, info.ModTime(), info.Size())
+}
+
+func read(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var instances []string
+	if err := json.NewDecoder(f).Decode(&instances); err != nil {
+		return nil, err
+	}
+
+	sort.Strings(instances)
+	return instances, nil
+}
+
+// update stores the given event, and notifies the subscribers if it differs
+// from the last.
+func (in *Instancer) update(event sd.Event, modTime time.Time, size int64) {
+	in.mtx.Lock()
+	defer in.mtx.Unlock()
+
+	in.modTime, in.size = modTime, size
+	if sameEvent(in.state, event) {
+		return
+	}
+
+	in.state = event
+	for ch := range in.subscribers {
+		ch <- copyEvent(event)
+	}
+}
+
+// Register implements github.com/go-kit/kit/sd.Instancer.  The current state
+// is sent to the channel immediately.
+func (in *Instancer) Register(ch chan<- sd.Event) {
+	in.mtx.Lock()
+	defer in.mtx.Unlock()
+
+	in.subscribers[ch] = struct{}{}
+	ch <- copyEvent(in.state)
+}
+
+// Deregister implements github.com/go-kit/kit/sd.Instancer.
+func (in *Instancer) Deregister(ch chan<- sd.Event) {
+	in.mtx.Lock()
+	defer in.mtx.Unlock()
+
+	delete(in.subscribers, ch)
+}
+
+// Stop implements github.com/go-kit/kit/sd.Instancer.  The file is no longer
+// checked for changes.
+func (in *Instancer) Stop() {
+	close(in.quit)
+}
+
+// sameEvent reports whether the events are the same.  Errors are compared by
+// their messages, as they are created anew every time the file is checked.
+func sameEvent(a, b sd.Event) bool {
+	if a.Err != nil || b.Err != nil {
+		return a.Err != nil && b.Err != nil && a.Err.Error() == b.Err.Error()
+	}
+
+	return reflect.DeepEqual(a.Instances, b.Instances)
+}
+
+// copyEvent copies the instances of the event, as subscribers are free to
+// modify them.
+func copyEvent(e sd.Event) sd.Event {
+	if e.Instances == nil {
+		return e
+	}
+
+	instances := make([]string, len(e.Instances))
+	copy(instances, e.Instances)
+	e.Instances = instances
+	return e
+}
@@ -0,0 +1,74 @@
+package file_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/sd"
+
+	"github.com/ayiga/go-kit-middlewarer/sd/file"
+)
+
+func writeInstances(t *testing.T, path, contents string) {
+	if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+		t.Fatalf("Unable to write instances: %s", err)
+	}
+}
+
+func nextEvent(t *testing.T, ch chan sd.Event) sd.Event {
+	select {
+	case event := <-ch:
+		return event
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for an event")
+		return sd.Event{}
+	}
+}
+
+func TestInstancer(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "instances.json")
+	writeInstances(t, path, `["b:8080", "a:8080"]`)
+
+	in := file.NewInstancer(path, 10*time.Millisecond, log.NewNopLogger())
+	defer in.Stop()
+
+	ch := make(chan sd.Event, 1)
+	in.Register(ch)
+	defer in.Deregister(ch)
+
+	if got, want := nextEvent(t, ch), (sd.Event{Instances: []string{"a:8080", "b:8080"}}); !reflect.DeepEqual(got, want) {
+		t.Errorf("Event:\ngot:\n\t%v\nwant:\n\t%v", got, want)
+	}
+
+	// ensure the modification time changes, regardless of its resolution.
+	writeInstances(t, path, `["c:8080"]`)
+	future := time.Now().Add(time.Minute)
+	os.Chtimes(path, future, future)
+
+	if got, want := nextEvent(t, ch), (sd.Event{Instances: []string{"c:8080"}}); !reflect.DeepEqual(got, want) {
+		t.Errorf("Event:\ngot:\n\t%v\nwant:\n\t%v", got, want)
+	}
+
+	writeInstances(t, path, `not json`)
+	os.Chtimes(path, future.Add(time.Minute), future.Add(time.Minute))
+
+	if got := nextEvent(t, ch); got.Err == nil {
+		t.Errorf("Event:\ngot:\n\t%v\nwant:\n\tan error", got)
+	}
+}
+
+func TestInstancerMissingFile(t *testing.T) {
+	in := file.NewInstancer(filepath.Join(t.TempDir(), "missing.json"), time.Hour, log.NewNopLogger())
+	defer in.Stop()
+
+	ch := make(chan sd.Event, 1)
+	in.Register(ch)
+
+	if got := nextEvent(t, ch); got.Err == nil || !os.IsNotExist(got.Err) {
+		t.Errorf("Event:\ngot:\n\t%v\nwant:\n\ta not exist error", got)
+	}
+}
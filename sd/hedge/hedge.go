@@ -0,0 +1,235 @@
+// Package hedge provides a github.com/go-kit/kit/sd/lb.Balancer that hedges
+// calls: if a call hasn't been answered within a percentile of the latencies
+// recently observed, the call is made again to a different address, and
+// whichever answers first is used.
+package hedge
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/go-kit/kit/sd/lb"
+
+	"github.com/ayiga/go-kit-middlewarer/encoding"
+)
+
+// Config represents how calls are hedged.
+type Config struct {
+	// Percentile is the percentile of the recently observed latencies to wait
+	// for, before hedging a call.  If zero, 0.95 is used.
+	Percentile float64
+
+	// Delay is how long to wait before hedging a call, until enough latencies
+	// have been observed.  If zero, 100ms is used.
+	Delay time.Duration
+}
+
+const (
+	// window is the number of latencies observed.
+	window = 100
+
+	// minSamples is the number of latencies needed for a percentile.
+	minSamples = 10
+
+	// attempts is the number of endpoints tried for the hedged call, before
+	// giving up on finding one at a different address.
+	attempts = 3
+)
+
+// latencies holds the most recently observed latencies.
+type latencies struct {
+	mu      sync.Mutex
+	samples []time.Duration
+	next    int
+}
+
+func (l *latencies) observe(d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if len(l.samples) < window {
+		l.samples = append(l.samples, d)
+		return
+	}
+
+	l.samples[l.next] = d
+	l.next = (l.next + 1) % window
+}
+
+// percentile returns the given percentile of the observed latencies, or false
+// if too few have been observed.
+func (l *latencies) percentile(p float64) (time.Duration, bool) {
+	l.mu.Lock()
+	samples := append([]time.Duration(nil), l.samples...)
+	l.mu.Unlock()
+
+	if len(samples) < minSamples {
+		return 0, false
+	}
+
+	sort.Slice(samples, func(i, j int) bool { return samples[i] < samples[j] })
+	i := int(p * float64(len(samples)))
+	if i >= len(samples) {
+		i = len(samples) - 1
+	}
+
+	return samples[i], true
+}
+
+type contextKey int
+
+const (
+	contextKeyHedge contextKey = iota
+)
+
+// errSameAddress is returned by an endpoint when a hedged call would be made
+// to the same address as the call it hedges.
+var errSameAddress = errors.New("the hedged call has the same address")
+
+// hedge tracks the addresses that a call, and the call hedging it, are made
+// to, so that they're made to different addresses.
+type hedge struct {
+	mu    sync.Mutex
+	addrs []string
+}
+
+// claim reports whether a call may be made to the given address.
+func (h *hedge) claim(addr string) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, a := range h.addrs {
+		if a == addr {
+			return false
+		}
+	}
+
+	h.addrs = append(h.addrs, addr)
+	return true
+}
+
+// Address returns a Middleware for the endpoints at the given address, which
+// refuses a call if the call it hedges has already been made to the address.
+// The endpoints a Balancer hedges with must be wrapped by it, else a call may
+// be hedged to the very address that is slow to answer it.
+func Address(addr string) endpoint.Middleware {
+	return func(next endpoint.Endpoint) endpoint.Endpoint {
+		return func(ctx context.Context, request interface{}) (interface{}, error) {
+			if h, ok := ctx.Value(contextKeyHedge).(*hedge); ok && !h.claim(addr) {
+				return nil, errSameAddress
+			}
+
+			return next(ctx, request)
+		}
+	}
+}
+
+// balancer returns endpoints that hedge their calls.
+type balancer struct {
+	lb.Balancer
+	config    Config
+	latencies *latencies
+}
+
+// Balancer returns a github.com/go-kit/kit/sd/lb.Balancer whose endpoints
+// hedge their calls, as specified by the Config, with the endpoints of the
+// given Balancer.  The latencies are observed across every call made with the
+// returned Balancer, so it should be used for a single method.
+//
+// Errors the server responded with, which the generated HTTP clients decode
+// as the response rather than the error, are failures as well, so a call that
+// is answered with one doesn't win over the call hedging it.
+func Balancer(b lb.Balancer, config Config) lb.Balancer {
+	return balancer{b, config, new(latencies)}
+}
+
+func (hb balancer) delay() time.Duration {
+	percentile := hb.config.Percentile
+	if percentile <= 0 {
+		percentile = 0.95
+	}
+
+	if d, ok := hb.latencies.percentile(percentile); ok {
+		return d
+	}
+
+	if hb.config.Delay <= 0 {
+		return 100 * time.Millisecond
+	}
+
+	return hb.config.Delay
+}
+
+type result struct {
+	response interface{}
+	err      error
+}
+
+func (hb balancer) Endpoint() (endpoint.Endpoint, error) {
+	primary, err := hb.Balancer.Endpoint()
+	if err != nil {
+		return nil, err
+	}
+
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		ctx, cancel := context.WithCancel(context.WithValue(ctx, contextKeyHedge, new(hedge)))
+		defer cancel()
+
+		results := make(chan result, 2)
+		go func() {
+			response, err := encoding.ResponseAsError(primary(ctx, request))
+			results <- result{response, err}
+		}()
+
+		begin := time.Now()
+		timer := time.NewTimer(hb.delay())
+		defer timer.Stop()
+
+		var firstErr error
+		for pending := 1; ; {
+			select {
+			case <-timer.C:
+				pending++
+				go func() {
+					results <- hb.hedge(ctx, request)
+				}()
+			case r := <-results:
+				pending--
+				if r.err == nil {
+					hb.latencies.observe(time.Since(begin))
+					return r.response, nil
+				}
+
+				if firstErr == nil || firstErr == errSameAddress {
+					firstErr = r.err
+				}
+				if pending == 0 {
+					// the error is returned as the response once more, if
+					// that's how the server responded with it
+					return encoding.ErrorAsResponse(nil, firstErr)
+				}
+			}
+		}
+	}, nil
+}
+
+// hedge makes the hedged call, to an endpoint at a different address.
+func (hb balancer) hedge(ctx context.Context, request interface{}) result {
+	for i := 0; i < attempts; i++ {
+		ep, err := hb.Balancer.Endpoint()
+		if err != nil {
+			return result{nil, err}
+		}
+
+		response, err := encoding.ResponseAsError(ep(ctx, request))
+		if err != errSameAddress {
+			return result{response, err}
+		}
+	}
+
+	return result{nil, errSameAddress}
+}
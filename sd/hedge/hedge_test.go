@@ -0,0 +1,105 @@
+package hedge_test
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/go-kit/kit/sd"
+	"github.com/go-kit/kit/sd/lb"
+
+	"github.com/ayiga/go-kit-middlewarer/sd/hedge"
+)
+
+type unavailableError struct{}
+
+func (unavailableError) Error() string   { return "unavailable" }
+func (unavailableError) StatusCode() int { return http.StatusServiceUnavailable }
+
+// answer returns an endpoint that answers with the given response after the
+// given delay, unless the call is cancelled first.
+func answer(delay time.Duration, response interface{}, cancelled *int32) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		select {
+		case <-time.After(delay):
+			return response, nil
+		case <-ctx.Done():
+			atomic.AddInt32(cancelled, 1)
+			return nil, ctx.Err()
+		}
+	}
+}
+
+func TestBalancerIgnoresErrorResponses(t *testing.T) {
+	var cancelled int32
+
+	// the primary is answered with a 503, after the call has been hedged, but
+	// before the hedge is answered
+	b := hedge.Balancer(lb.NewRoundRobin(sd.FixedEndpointer{
+		hedge.Address("a")(answer(30*time.Millisecond, unavailableError{}, &cancelled)),
+		hedge.Address("b")(answer(60*time.Millisecond, "OK", &cancelled)),
+	}), hedge.Config{Delay: 10 * time.Millisecond})
+
+	ep, err := b.Endpoint()
+	if err != nil {
+		t.Fatalf("Unable to get an Endpoint: %s", err)
+	}
+
+	response, err := ep(context.Background(), nil)
+	if err != nil || response != "OK" {
+		t.Errorf("Response:\ngot:\n\t%#v %v\nwant:\n\t%#v <nil>", response, err, "OK")
+	}
+	if got := atomic.LoadInt32(&cancelled); got != 0 {
+		t.Errorf("Expected no call to be cancelled, got: %d", got)
+	}
+}
+
+func TestBalancerReturnsErrorResponses(t *testing.T) {
+	var cancelled int32
+
+	// when every call is answered with an error, it's returned as the
+	// response, as it was answered
+	b := hedge.Balancer(lb.NewRoundRobin(sd.FixedEndpointer{
+		hedge.Address("a")(answer(20*time.Millisecond, unavailableError{}, &cancelled)),
+		hedge.Address("b")(answer(20*time.Millisecond, unavailableError{}, &cancelled)),
+	}), hedge.Config{Delay: 10 * time.Millisecond})
+
+	ep, err := b.Endpoint()
+	if err != nil {
+		t.Fatalf("Unable to get an Endpoint: %s", err)
+	}
+
+	response, err := ep(context.Background(), nil)
+	if _, ok := response.(unavailableError); !ok || err != nil {
+		t.Errorf("Response:\ngot:\n\t%#v %v\nwant:\n\t%#v <nil>", response, err, unavailableError{})
+	}
+}
+
+func TestBalancerHedgesToDifferentAddresses(t *testing.T) {
+	var calls, cancelled int32
+	slow := answer(50*time.Millisecond, "OK", &cancelled)
+
+	// every endpoint is at the same address, so the call is never hedged
+	b := hedge.Balancer(lb.NewRoundRobin(sd.FixedEndpointer{
+		hedge.Address("a")(func(ctx context.Context, request interface{}) (interface{}, error) {
+			atomic.AddInt32(&calls, 1)
+			return slow(ctx, request)
+		}),
+	}), hedge.Config{Delay: 10 * time.Millisecond})
+
+	ep, err := b.Endpoint()
+	if err != nil {
+		t.Fatalf("Unable to get an Endpoint: %s", err)
+	}
+
+	response, err := ep(context.Background(), nil)
+	if err != nil || response != "OK" {
+		t.Errorf("Response:\ngot:\n\t%#v %v\nwant:\n\t%#v <nil>", response, err, "OK")
+	}
+	if got := atomic.LoadInt32(&calls); got != 1 {
+		t.Errorf("Calls:\ngot:\n\t%d\nwant:\n\t%d", got, 1)
+	}
+}
@@ -8,6 +8,7 @@ import (
 type Struct struct {
 	name  string // the name of the constant.
 	types []Type
+	typ   *ast.StructType
 
 	pkg  *Package
 	file File
@@ -17,6 +18,7 @@ func createStruct(name string, iface *ast.StructType, file File) Struct {
 	stru := Struct{
 		name:  name,
 		types: nil,
+		typ:   iface,
 	}
 
 	return stru
@@ -0,0 +1,306 @@
+// Package telemetry implements the OpenTelemetry tracing and metrics recorded
+// by the generated otel layers.
+//
+// Spans are named after the interface and method they cover, such as
+// StringService.Uppercase, and W3C trace context is propagated between the
+// generated clients and servers by the RequestFuncs of a Telemetry.
+package telemetry
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/ayiga/go-kit-middlewarer/encoding"
+	"github.com/ayiga/go-kit-middlewarer/logs"
+)
+
+// InstrumentationName is the name of the Tracer and Meter used to record
+// spans and metrics.
+const InstrumentationName = "github.com/ayiga/go-kit-middlewarer/telemetry"
+
+// Config represents where spans and metrics are recorded, and how trace
+// context is propagated.
+type Config struct {
+	// TracerProvider provides the Tracer spans are recorded with.  If nil,
+	// otel.GetTracerProvider() is used.
+	TracerProvider trace.TracerProvider
+
+	// MeterProvider provides the Meter metrics are recorded with.  If nil,
+	// otel.GetMeterProvider() is used.
+	MeterProvider metric.MeterProvider
+
+	// Propagator injects and extracts trace context from the headers of
+	// requests.  If nil, W3C trace context is propagated.
+	Propagator propagation.TextMapPropagator
+}
+
+// instruments are the metrics recorded for a single span kind.
+type instruments struct {
+	duration metric.Float64Histogram
+	requests metric.Int64Counter
+}
+
+// Telemetry records the spans and metrics of method calls.
+type Telemetry struct {
+	tracer     trace.Tracer
+	propagator propagation.TextMapPropagator
+	server     instruments
+	client     instruments
+}
+
+// New returns a Telemetry recording spans and metrics as specified by the
+// given Config.  The metrics recorded are:
+//
+//	rpc.server.duration and rpc.client.duration, histograms of how long
+//	calls took, in seconds.
+//	rpc.server.requests and rpc.client.requests, counters of the calls made.
+//
+// Each is attributed with the rpc.service and rpc.method of the call, and its
+// error.type if it failed.
+func New(c Config) (*Telemetry, error) {
+	if c.TracerProvider == nil {
+		c.TracerProvider = otel.GetTracerProvider()
+	}
+	if c.MeterProvider == nil {
+		c.MeterProvider = otel.GetMeterProvider()
+	}
+	if c.Propagator == nil {
+		c.Propagator = propagation.TraceContext{}
+	}
+
+	meter := c.MeterProvider.Meter(InstrumentationName)
+	server, err := newInstruments(meter, "server")
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := newInstruments(meter, "client")
+	if err != nil {
+		return nil, err
+	}
+
+	return &Telemetry{
+		tracer:     c.TracerProvider.Tracer(InstrumentationName),
+		propagator: c.Propagator,
+		server:     server,
+		client:     client,
+	}, nil
+}
+
+func newInstruments(meter metric.Meter, kind string) (instruments, error) {
+	duration, err := meter.Float64Histogram(
+		"rpc."+kind+".duration",
+		metric.WithDescription("How long calls took."),
+		metric.WithUnit("s"),
+	)
+	if err != nil {
+		return instruments{}, err
+	}
+
+	requests, err := meter.Int64Counter(
+		"rpc."+kind+".requests",
+		metric.WithDescription("The number of calls made."),
+		metric.WithUnit("{request}"),
+	)
+	if err != nil {
+		return instruments{}, err
+	}
+
+	return instruments{duration: duration, requests: requests}, nil
+}
+
+// Call represents an in progress method call, started by Start.
+type Call struct {
+	span        trace.Span
+	kind        trace.SpanKind
+	attrs       []attribute.KeyValue
+	begin       time.Time
+	instruments *instruments
+}
+
+// Start starts a span of the given kind for a call to the method of the given
+// service, returning a copy of ctx holding it.  The trace ID of the span is
+// also stored within ctx by logs.WithTraceID.  The Call must be ended with
+// End.
+//
+// Server and client spans record metrics, while internal spans, such as
+// those of the generated service middleware, only record the span.
+func (t *Telemetry) Start(ctx context.Context, kind trace.SpanKind, service, method string) (context.Context, *Call) {
+	attrs := []attribute.KeyValue{
+		attribute.String("rpc.system", "go-kit"),
+		attribute.String("rpc.service", service),
+		attribute.String("rpc.method", method),
+	}
+
+	ctx, span := t.tracer.Start(ctx, service+"."+method,
+		trace.WithSpanKind(kind),
+		trace.WithAttributes(attrs...),
+	)
+	if sc := span.SpanContext(); sc.HasTraceID() {
+		ctx = logs.WithTraceID(ctx, sc.TraceID().String())
+	}
+
+	c := &Call{
+		span:  span,
+		kind:  kind,
+		attrs: attrs[1:],
+		begin: time.Now(),
+	}
+	switch kind {
+	case trace.SpanKindServer:
+		c.instruments = &t.server
+	case trace.SpanKindClient:
+		c.instruments = &t.client
+	}
+
+	return ctx, c
+}
+
+// End ends the Call, with the error it failed with, if any.  Errors are
+// recorded on the span, which is given an error status.
+//
+// Server spans are attributed with the HTTP status code of the response.  When
+// the request is served by Handler, the span is only ended once the response
+// has been written, with the status code it was written with; otherwise, the
+// status code is that the error is responded with, or 200 OK.
+func (c *Call) End(ctx context.Context, err error) {
+	if c.kind == trace.SpanKindServer {
+		if p, ok := ctx.Value(contextKeyPending).(*pending); ok && p.hold(ctx, c, err) {
+			return
+		}
+	}
+
+	c.end(ctx, err, 0)
+}
+
+func (c *Call) end(ctx context.Context, err error, status int) {
+	attrs := c.attrs
+	if err != nil {
+		c.span.RecordError(err)
+		c.span.SetStatus(codes.Error, err.Error())
+		attrs = append(attrs[:len(attrs):len(attrs)], attribute.String("error.type", fmt.Sprintf("%T", err)))
+	}
+
+	if c.kind == trace.SpanKindServer {
+		if status == 0 {
+			status = http.StatusOK
+			if err != nil {
+				status = encoding.ErrorStatus(err)
+			}
+		}
+
+		c.span.SetAttributes(attribute.Int("http.response.status_code", status))
+	}
+
+	c.span.End()
+
+	if c.instruments != nil {
+		set := metric.WithAttributes(attrs...)
+		c.instruments.duration.Record(ctx, time.Since(c.begin).Seconds(), set)
+		c.instruments.requests.Add(ctx, 1, set)
+	}
+}
+
+type contextKey int
+
+const (
+	contextKeyPending contextKey = iota
+)
+
+// pending holds the server Call of a request served by Handler, until its
+// response has been written.
+type pending struct {
+	mu   sync.Mutex
+	ctx  context.Context
+	call *Call
+	err  error
+}
+
+// hold holds the given Call, unless one is held already.
+func (p *pending) hold(ctx context.Context, c *Call, err error) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.call != nil {
+		return false
+	}
+
+	p.ctx, p.call, p.err = ctx, c, err
+	return true
+}
+
+// end ends the held Call, if any, with the status code of the response.
+func (p *pending) end(status int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.call != nil {
+		p.call.end(p.ctx, p.err, status)
+	}
+}
+
+// statusRecorder records the status code a response is written with.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(p []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+
+	return r.ResponseWriter.Write(p)
+}
+
+// Handler wraps the given net/http.Handler, so that the server span of each
+// request is ended once its response has been written, attributed with the
+// status code it was written with, whether the call succeeded or not.
+func (t *Telemetry) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p := new(pending)
+		rec := &statusRecorder{ResponseWriter: w}
+		defer func() {
+			status := rec.status
+			if status == 0 {
+				status = http.StatusOK
+			}
+
+			p.end(status)
+		}()
+
+		next.ServeHTTP(rec, r.WithContext(context.WithValue(r.Context(), contextKeyPending, p)))
+	})
+}
+
+// HTTPToContext is a github.com/go-kit/kit/transport/http.RequestFunc that
+// extracts the trace context propagated with the incoming Request, so server
+// spans continue the trace of the client.
+func (t *Telemetry) HTTPToContext(ctx context.Context, r *http.Request) context.Context {
+	return t.propagator.Extract(ctx, propagation.HeaderCarrier(r.Header))
+}
+
+// ContextToHTTP is a github.com/go-kit/kit/transport/http.RequestFunc that
+// injects the trace context of the client span into the outgoing Request.
+func (t *Telemetry) ContextToHTTP(ctx context.Context, r *http.Request) context.Context {
+	t.propagator.Inject(ctx, propagation.HeaderCarrier(r.Header))
+	return ctx
+}
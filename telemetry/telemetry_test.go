@@ -0,0 +1,207 @@
+package telemetry_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/ayiga/go-kit-middlewarer/logs"
+	"github.com/ayiga/go-kit-middlewarer/telemetry"
+)
+
+func newTelemetry(t *testing.T) (*telemetry.Telemetry, *tracetest.SpanRecorder, *sdkmetric.ManualReader) {
+	recorder := tracetest.NewSpanRecorder()
+	reader := sdkmetric.NewManualReader()
+
+	tel, err := telemetry.New(telemetry.Config{
+		TracerProvider: sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)),
+		MeterProvider:  sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)),
+	})
+	if err != nil {
+		t.Fatalf("New: %s", err)
+	}
+
+	return tel, recorder, reader
+}
+
+func TestServerCall(t *testing.T) {
+	tel, recorder, reader := newTelemetry(t)
+
+	ctx, call := tel.Start(context.Background(), trace.SpanKindServer, "StringService", "Uppercase")
+	if _, ok := logs.TraceID(ctx); !ok {
+		t.Errorf("TraceID: expected the trace ID to be stored within the context")
+	}
+	call.End(ctx, errors.New("boom"))
+
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("expected 1 span, got %d", len(spans))
+	}
+
+	s := spans[0]
+	if got, want := s.Name(), "StringService.Uppercase"; got != want {
+		t.Errorf("Name:\ngot:\n\t%s\nwant:\n\t%s", got, want)
+	}
+	if got, want := s.SpanKind(), trace.SpanKindServer; got != want {
+		t.Errorf("SpanKind:\ngot:\n\t%s\nwant:\n\t%s", got, want)
+	}
+	if got, want := s.Status().Code, codes.Error; got != want {
+		t.Errorf("Status:\ngot:\n\t%s\nwant:\n\t%s", got, want)
+	}
+	if len(s.Events()) != 1 || s.Events()[0].Name != "exception" {
+		t.Errorf("expected the error to be recorded, got events %v", s.Events())
+	}
+	if got, want := statusCode(s), int64(http.StatusInternalServerError); got != want {
+		t.Errorf("http.response.status_code:\ngot:\n\t%d\nwant:\n\t%d", got, want)
+	}
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatalf("Collect: %s", err)
+	}
+
+	found := map[string]bool{}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			found[m.Name] = true
+			if sum, ok := m.Data.(metricdata.Sum[int64]); ok && sum.DataPoints[0].Value != 1 {
+				t.Errorf("%s:\ngot:\n\t%d\nwant:\n\t%d", m.Name, sum.DataPoints[0].Value, 1)
+			}
+		}
+	}
+	for _, name := range []string{"rpc.server.duration", "rpc.server.requests"} {
+		if !found[name] {
+			t.Errorf("expected the %s metric to be recorded, got %v", name, found)
+		}
+	}
+}
+
+func TestInternalCallRecordsNoMetrics(t *testing.T) {
+	tel, recorder, reader := newTelemetry(t)
+
+	ctx, call := tel.Start(context.Background(), trace.SpanKindInternal, "StringService", "Count")
+	call.End(ctx, nil)
+
+	if got := len(recorder.Ended()); got != 1 {
+		t.Fatalf("expected 1 span, got %d", got)
+	}
+	if got, want := recorder.Ended()[0].Status().Code, codes.Unset; got != want {
+		t.Errorf("Status:\ngot:\n\t%s\nwant:\n\t%s", got, want)
+	}
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatalf("Collect: %s", err)
+	}
+	for _, sm := range rm.ScopeMetrics {
+		if len(sm.Metrics) != 0 {
+			t.Errorf("expected no metrics, got %v", sm.Metrics)
+		}
+	}
+}
+
+func TestPropagation(t *testing.T) {
+	tel, recorder, _ := newTelemetry(t)
+
+	ctx, client := tel.Start(context.Background(), trace.SpanKindClient, "StringService", "Count")
+	r, _ := http.NewRequest("GET", "http://localhost/count", nil)
+	tel.ContextToHTTP(ctx, r)
+	if r.Header.Get("Traceparent") == "" {
+		t.Fatalf("expected a traceparent header to be injected")
+	}
+
+	ctx, server := tel.Start(tel.HTTPToContext(context.Background(), r), trace.SpanKindServer, "StringService", "Count")
+	server.End(ctx, nil)
+	client.End(ctx, nil)
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("expected 2 spans, got %d", len(spans))
+	}
+	if got, want := spans[0].Parent().SpanID(), spans[1].SpanContext().SpanID(); got != want {
+		t.Errorf("Parent:\ngot:\n\t%s\nwant:\n\t%s", got, want)
+	}
+}
+
+// statusCode returns the http.response.status_code of the span, or zero.
+func statusCode(s sdktrace.ReadOnlySpan) int64 {
+	for _, kv := range s.Attributes() {
+		if kv.Key == attribute.Key("http.response.status_code") {
+			return kv.Value.AsInt64()
+		}
+	}
+
+	return 0
+}
+
+func TestServerCallSucceeded(t *testing.T) {
+	tel, recorder, _ := newTelemetry(t)
+
+	ctx, call := tel.Start(context.Background(), trace.SpanKindServer, "StringService", "Count")
+	call.End(ctx, nil)
+
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("expected 1 span, got %d", len(spans))
+	}
+	if got, want := statusCode(spans[0]), int64(http.StatusOK); got != want {
+		t.Errorf("http.response.status_code:\ngot:\n\t%d\nwant:\n\t%d", got, want)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	tests := []struct {
+		err    error
+		status int
+	}{
+		{nil, http.StatusCreated},
+		{errors.New("boom"), http.StatusTeapot},
+		{nil, 0},
+	}
+
+	for _, test := range tests {
+		tel, recorder, _ := newTelemetry(t)
+
+		h := tel.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx, call := tel.Start(r.Context(), trace.SpanKindServer, "StringService", "Count")
+			call.End(ctx, test.err)
+
+			// the span is ended once the response has been written
+			if got := len(recorder.Ended()); got != 0 {
+				t.Errorf("expected no span to be ended yet, got %d", got)
+			}
+
+			if test.status != 0 {
+				w.WriteHeader(test.status)
+			}
+			w.Write([]byte("{}"))
+		}))
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/count", nil))
+
+		want := int64(test.status)
+		if want == 0 {
+			want = http.StatusOK
+		}
+
+		spans := recorder.Ended()
+		if len(spans) != 1 {
+			t.Fatalf("expected 1 span, got %d", len(spans))
+		}
+		if got := statusCode(spans[0]); got != want {
+			t.Errorf("http.response.status_code:\ngot:\n\t%d\nwant:\n\t%d", got, want)
+		}
+		if failed := spans[0].Status().Code == codes.Error; failed != (test.err != nil) {
+			t.Errorf("Status:\ngot:\n\t%s\nwant error:\n\t%t", spans[0].Status().Code, test.err != nil)
+		}
+	}
+}
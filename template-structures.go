@@ -3,6 +3,7 @@ package main
 import (
 	"fmt"
 	"strings"
+	"time"
 )
 
 type TemplateCommon struct {
@@ -21,6 +22,10 @@ type TemplateParam struct {
 	Name       string
 	Type       string
 	IsContext  bool
+
+	// Convert represents whether a conversion to Type is needed, when
+	// assigning the TemplateParam from its counterpart in another version.
+	Convert bool
 }
 
 func createTemplateParam(p Param) TemplateParam {
@@ -47,6 +52,217 @@ type TemplateMethod struct {
 	MethodResultNames      []string
 	Params                 []TemplateParam
 	Results                []TemplateParam
+	Versions               []TemplateVersion
+	Validations            []TemplateValidation
+	Idempotent             bool
+	Hedged                 bool
+	Cached                 bool
+
+	// CacheTTL is how long the results are cached for, as a Go expression,
+	// or empty if the default is to be used.
+	CacheTTL string
+
+	// Auth represents whether the accepted AuthSchemes were annotated, and
+	// are empty if credentials aren't required.
+	Auth        bool
+	AuthSchemes []string
+
+	// Scopes must all be granted to call the method, along with one of the
+	// Roles, if any.
+	Scopes []string
+	Roles  []string
+
+	// LoggedArguments and LoggedResults are the arguments and results that
+	// are logged, excluding those annotated to be omitted.
+	LoggedArguments []TemplateLogValue
+	LoggedResults   []TemplateLogValue
+}
+
+// TemplateLogValue represents an argument or result of a TemplateMethod that
+// is logged, whose value is replaced with a placeholder if Redact is set.
+type TemplateLogValue struct {
+	Name   string
+	Redact bool
+
+	// Attr is the log/slog function the value is logged as an attribute
+	// with, such as Int64, after being converted to the Convert type, if any.
+	Attr    string
+	Convert string
+}
+
+// slogAttrs are the log/slog functions, and the types they accept, values of
+// the basic types are logged as attributes with.  Values of any other type
+// are logged with slog.Any.
+var slogAttrs = map[string][2]string{
+	"string":        {"String", ""},
+	"bool":          {"Bool", ""},
+	"int":           {"Int", ""},
+	"int8":          {"Int64", "int64"},
+	"int16":         {"Int64", "int64"},
+	"int32":         {"Int64", "int64"},
+	"rune":          {"Int64", "int64"},
+	"int64":         {"Int64", ""},
+	"uint":          {"Uint64", "uint64"},
+	"uint8":         {"Uint64", "uint64"},
+	"byte":          {"Uint64", "uint64"},
+	"uint16":        {"Uint64", "uint64"},
+	"uint32":        {"Uint64", "uint64"},
+	"uint64":        {"Uint64", ""},
+	"float32":       {"Float64", "float64"},
+	"float64":       {"Float64", ""},
+	"time.Duration": {"Duration", ""},
+	"time.Time":     {"Time", ""},
+}
+
+func createTemplateLogValues(meth Method, ps []Param) []TemplateLogValue {
+	var result []TemplateLogValue
+	for _, p := range ps {
+		if p.typ.String() == "context.Context" {
+			continue
+		}
+
+		attr, ok := slogAttrs[p.typ.String()]
+		if !ok {
+			attr = [2]string{"Any", ""}
+		}
+
+		for _, n := range p.names {
+			if sliceContains(meth.logOmit, n) {
+				continue
+			}
+
+			result = append(result, TemplateLogValue{
+				Name:    n,
+				Redact:  sliceContains(meth.logRedact, n),
+				Attr:    attr[0],
+				Convert: attr[1],
+			})
+		}
+	}
+	return result
+}
+
+// TemplateVersion represents a prior version of a TemplateMethod's signature.
+type TemplateVersion struct {
+	Name       string
+	PublicName string
+	Params     []TemplateParam
+	Results    []TemplateParam
+
+	// CommonParams are the current Params that are able to be assigned from
+	// this version, and CommonResults are the Results of this version that
+	// are able to be assigned from the current version.
+	CommonParams  []TemplateParam
+	CommonResults []TemplateParam
+}
+
+// TemplateValidation represents a parameter of a TemplateMethod that is to be
+// validated.  Parameters without Rules are only checked against the validate
+// tags of their struct fields.
+type TemplateValidation struct {
+	Name       string
+	PublicName string
+	Rules      string
+}
+
+// basicTypes are the types that are unable to carry validate tags.
+var basicTypes = append([]string{
+	"string", "bool", "byte", "rune", "error", "[]byte", "context.Context",
+}, numericTypes...)
+
+func createTemplateValidations(meth Method) []TemplateValidation {
+	if !meth.hasErrResult {
+		// there's no way to report a failure
+		return nil
+	}
+
+	var result []TemplateValidation
+	for _, p := range meth.params {
+		for _, n := range p.names {
+			tv := TemplateValidation{
+				Name:       n,
+				PublicName: publicVariableName(n),
+			}
+			for _, v := range meth.validations {
+				if v.param == n {
+					tv.Rules = v.rules
+				}
+			}
+
+			if tv.Rules != "" || !sliceContains(basicTypes, p.typ.String()) {
+				result = append(result, tv)
+			}
+		}
+	}
+	return result
+}
+
+func createTemplateParams(ps []Param) []TemplateParam {
+	var result []TemplateParam
+	for _, p := range ps {
+		for _, n := range p.names {
+			param := TemplateParam{
+				PublicName: publicVariableName(n),
+				Name:       n,
+				Type:       p.typ.String(),
+			}
+			if param.Type == "context.Context" {
+				param.IsContext = true
+			}
+
+			result = append(result, param)
+		}
+	}
+	return result
+}
+
+var numericTypes = []string{
+	"int", "int8", "int16", "int32", "int64",
+	"uint", "uint8", "uint16", "uint32", "uint64",
+	"float32", "float64",
+}
+
+// commonTemplateParams returns the TemplateParams within to that have a
+// counterpart, of the same name, within from.  Counterparts must share the
+// same type, unless both are numeric, in which case they're converted.
+func commonTemplateParams(to, from []TemplateParam) []TemplateParam {
+	var result []TemplateParam
+	for _, t := range to {
+		if t.IsContext {
+			continue
+		}
+
+		for _, f := range from {
+			if t.PublicName != f.PublicName {
+				continue
+			}
+
+			if t.Type == f.Type {
+				result = append(result, t)
+			} else if sliceContains(numericTypes, t.Type) && sliceContains(numericTypes, f.Type) {
+				t.Convert = true
+				result = append(result, t)
+			}
+			break
+		}
+	}
+	return result
+}
+
+func createTemplateVersions(versions []MethodVersion, params, results []TemplateParam) []TemplateVersion {
+	var result []TemplateVersion
+	for _, v := range versions {
+		tv := TemplateVersion{
+			Name:       v.name,
+			PublicName: publicVariableName(v.name),
+			Params:     createTemplateParams(v.params),
+			Results:    createTemplateParams(v.results),
+		}
+		tv.CommonParams = commonTemplateParams(params, tv.Params)
+		tv.CommonResults = commonTemplateParams(tv.Results, results)
+		result = append(result, tv)
+	}
+	return result
 }
 
 func publicVariableName(str string) string {
@@ -149,11 +365,53 @@ func createTemplateMethods(basePackage, endpointPackage *Import, interf Interfac
 			MethodResultNames:      resultNames,
 			Params:                 params,
 			Results:                methodsResults,
+			Versions:               createTemplateVersions(meth.versions, params, methodsResults),
+			Validations:            createTemplateValidations(meth),
+			Idempotent:             meth.idempotent,
+			Hedged:                 meth.hedged,
+			Cached:                 meth.cached,
+			CacheTTL:               durationExpr(meth.cacheTTL),
+			Auth:                   meth.auth,
+			AuthSchemes:            meth.authSchemes,
+			Scopes:                 meth.scopes,
+			Roles:                  meth.roles,
+			LoggedArguments:        createTemplateLogValues(meth, meth.params),
+			LoggedResults:          createTemplateLogValues(meth, meth.results),
 		})
 	}
 	return results
 }
 
+// durationUnits are the units durationExpr expresses durations in, from the
+// largest to the smallest.
+var durationUnits = []struct {
+	d    time.Duration
+	name string
+}{
+	{time.Hour, "time.Hour"},
+	{time.Minute, "time.Minute"},
+	{time.Second, "time.Second"},
+	{time.Millisecond, "time.Millisecond"},
+	{time.Microsecond, "time.Microsecond"},
+	{time.Nanosecond, "time.Nanosecond"},
+}
+
+// durationExpr returns the given duration as a Go expression, such as
+// 30 * time.Second, or an empty string if it is zero.
+func durationExpr(d time.Duration) string {
+	if d == 0 {
+		return ""
+	}
+
+	for _, u := range durationUnits {
+		if d%u.d == 0 {
+			return fmt.Sprintf("%d * %s", d/u.d, u.name)
+		}
+	}
+
+	return ""
+}
+
 type TemplateBase struct {
 	TemplateCommon
 	Imports            []string
@@ -161,6 +419,17 @@ type TemplateBase struct {
 	ExtraImports       []string
 	Methods            []TemplateMethod
 	ExtraInterfaces    []TemplateParam
+	Sentinels          []string
+
+	// UsesContext represents whether any of the Methods has a
+	// context.Context parameter.  The context import is filtered out of
+	// Imports, so templates that don't otherwise use it must import it when
+	// this is set.
+	UsesContext bool
+
+	// HasValidations represents whether any of the Methods has an argument
+	// to validate, and so whether the validate package is used.
+	HasValidations bool
 }
 
 func createTemplateBase(basePackage, endpointPackage *Import, i Interface, oimps []*Import) TemplateBase {
@@ -206,6 +475,13 @@ func createTemplateBase(basePackage, endpointPackage *Import, i Interface, oimps
 		})
 	}
 
+	usesContext := false
+	hasValidations := false
+	for _, m := range i.methods {
+		usesContext = usesContext || m.hasContextParam
+		hasValidations = hasValidations || len(createTemplateValidations(m)) > 0
+	}
+
 	return TemplateBase{
 		TemplateCommon: TemplateCommon{
 			BasePackage:         basePackage.path,
@@ -222,6 +498,8 @@ func createTemplateBase(basePackage, endpointPackage *Import, i Interface, oimps
 		ExtraImports:       extraImpSpecs,
 		Methods:            createTemplateMethods(basePackage, endpointPackage, i, i.methods, names),
 		ExtraInterfaces:    extraInterfaces,
+		UsesContext:        usesContext,
+		HasValidations:     hasValidations,
 	}
 }
 
@@ -0,0 +1,371 @@
+// Package validate implements the rules checked by the generated validation
+// middleware, along with the ValidationError returned when they fail.
+//
+// Rules are a comma separated list, such as "required,max=256", and are
+// declared for the parameters of a method with a validate annotation:
+//
+//	// @validate str required,max=256
+//
+// Struct parameters, and the structs they contain, are additionally checked
+// against the rules within the validate tag of each of their fields:
+//
+//	type Person struct {
+//		Name string `json:"name" validate:"required"`
+//	}
+package validate
+
+import (
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/ayiga/go-kit-middlewarer/encoding"
+)
+
+func init() {
+	gob.Register(ValidationError{})
+	encoding.RegisterError(ValidationError{})
+}
+
+// FieldError describes a single field that failed validation.
+type FieldError struct {
+	Field   string `json:"field" xml:"field"`
+	Message string `json:"message" xml:"message"`
+}
+
+func (fe FieldError) Error() string {
+	return fe.Field + " " + fe.Message
+}
+
+// ValidationError is returned when the arguments of a request fail
+// validation.  It is registered with the encoding package, so that clients
+// receive it as a ValidationError, and is responded with as a 400 Bad Request.
+type ValidationError struct {
+	Fields []FieldError `json:"fields" xml:"field"`
+}
+
+func (ve ValidationError) Error() string {
+	messages := make([]string, 0, len(ve.Fields))
+	for _, f := range ve.Fields {
+		messages = append(messages, f.Error())
+	}
+
+	return "validation failed: " + strings.Join(messages, "; ")
+}
+
+// StatusCode implements github.com/go-kit/kit/transport/http.StatusCoder
+func (ve ValidationError) StatusCode() int {
+	return http.StatusBadRequest
+}
+
+// ErrUnknownRule is returned by Parse when a rule is not one of required,
+// min, max, len, oneof or pattern.
+var ErrUnknownRule = errors.New("Unknown validation rule")
+
+// ErrInvalidRule is returned by Parse when the argument of a rule is not
+// valid for it.
+var ErrInvalidRule = errors.New("Invalid validation rule argument")
+
+// ErrInvalidTag is returned by Check and Struct when the validate tag of a
+// struct field is unable to be parsed.
+var ErrInvalidTag = errors.New("Invalid validate tag")
+
+// Rule is a single, parsed, validation rule.
+type Rule struct {
+	Name string
+	Arg  string
+
+	number  float64
+	options []string
+	expr    *regexp.Regexp
+}
+
+// Rules are the parsed rules to check a value against.
+type Rules []Rule
+
+// Parse parses a comma separated list of rules.  The supported rules are:
+//
+//	required       the value must not be its zero value, or nil.
+//	min=N, max=N   strings, slices and maps must have at least, or at most,
+//	               N elements; numbers must be at least, or at most, N.
+//	len=N          strings, slices and maps must have exactly N elements.
+//	oneof=a b c    the value must be one of the space separated options.
+//	pattern=expr   strings must match the regular expression, which cannot
+//	               contain a comma.
+//
+// Rules other than required are skipped for nil values.
+func Parse(s string) (Rules, error) {
+	var rules Rules
+	for _, piece := range strings.Split(s, ",") {
+		piece = strings.TrimSpace(piece)
+		if piece == "" {
+			continue
+		}
+
+		pieces := strings.SplitN(piece, "=", 2)
+		r := Rule{
+			Name: pieces[0],
+		}
+		if len(pieces) > 1 {
+			r.Arg = pieces[1]
+		}
+
+		var err error
+		switch r.Name {
+		case "required":
+			if len(pieces) > 1 {
+				err = errors.New("takes no argument")
+			}
+		case "min", "max", "len":
+			r.number, err = strconv.ParseFloat(r.Arg, 64)
+		case "oneof":
+			r.options = strings.Fields(r.Arg)
+			if len(r.options) == 0 {
+				err = errors.New("requires at least one option")
+			}
+		case "pattern":
+			r.expr, err = regexp.Compile(r.Arg)
+		default:
+			return nil, fmt.Errorf("%w: %s", ErrUnknownRule, r.Name)
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %s", ErrInvalidRule, piece, err)
+		}
+
+		rules = append(rules, r)
+	}
+
+	return rules, nil
+}
+
+// MustParse is like Parse, but panics if the rules are unable to be parsed.
+// It is intended for the initialization of package variables.
+func MustParse(s string) Rules {
+	rules, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+
+	return rules
+}
+
+// Check checks the given value, identified by field, against the Rules.  If
+// the value is a struct, or a pointer to one, its fields are then checked
+// against the rules within their validate tags.  Every failure is returned,
+// or an error wrapping ErrInvalidTag if a validate tag is unable to be parsed.
+func (rules Rules) Check(field string, value interface{}) ([]FieldError, error) {
+	return rules.check(field, reflect.ValueOf(value))
+}
+
+func (rules Rules) check(field string, v reflect.Value) ([]FieldError, error) {
+	for v.IsValid() && (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			v = reflect.Value{}
+			break
+		}
+		v = v.Elem()
+	}
+
+	var errs []FieldError
+	for _, r := range rules {
+		if r.Name != "required" && !v.IsValid() {
+			continue
+		}
+
+		if msg := r.check(v); msg != "" {
+			errs = append(errs, FieldError{Field: field, Message: msg})
+		}
+	}
+
+	if v.IsValid() && v.Kind() == reflect.Struct {
+		fieldErrs, err := checkStruct(field, v)
+		if err != nil {
+			return nil, err
+		}
+		errs = append(errs, fieldErrs...)
+	}
+
+	return errs, nil
+}
+
+// Struct checks the fields of the given struct against the rules within
+// their validate tags.
+func Struct(field string, value interface{}) ([]FieldError, error) {
+	return Rules(nil).Check(field, value)
+}
+
+// ParseTag parses the rules within the validate tag of a struct field, as
+// found within the given struct tag, returning false if there are none.
+func ParseTag(tag reflect.StructTag) (Rules, bool, error) {
+	s, ok := tag.Lookup("validate")
+	if !ok {
+		return nil, false, nil
+	}
+
+	rules, err := Parse(s)
+	if err != nil {
+		return nil, true, fmt.Errorf("%w: %s", ErrInvalidTag, err)
+	}
+
+	return rules, true, nil
+}
+
+// fieldRules are the rules a field of a struct is checked against.
+type fieldRules struct {
+	index int
+	name  string
+	rules Rules
+}
+
+// structRules are the parsed validate tags of a struct, or the error parsing
+// them.
+type structRules struct {
+	fields []fieldRules
+	err    error
+}
+
+// parsedStructs holds the structRules of each struct type checked, so that
+// their tags are parsed only once.
+var parsedStructs sync.Map
+
+func parseStruct(t reflect.Type) structRules {
+	if sr, ok := parsedStructs.Load(t); ok {
+		return sr.(structRules)
+	}
+
+	var sr structRules
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			// unexported
+			continue
+		}
+
+		rules, ok, err := ParseTag(f.Tag)
+		if err != nil {
+			sr = structRules{err: fmt.Errorf("%s.%s: %w", t, f.Name, err)}
+			break
+		}
+		if !ok && !isStruct(f.Type) {
+			continue
+		}
+
+		sr.fields = append(sr.fields, fieldRules{i, fieldName(f), rules})
+	}
+
+	parsedStructs.Store(t, sr)
+	return sr
+}
+
+func checkStruct(field string, v reflect.Value) ([]FieldError, error) {
+	sr := parseStruct(v.Type())
+	if sr.err != nil {
+		return nil, sr.err
+	}
+
+	var errs []FieldError
+	for _, f := range sr.fields {
+		fieldErrs, err := f.rules.check(joinField(field, f.name), v.Field(f.index))
+		if err != nil {
+			return nil, err
+		}
+		errs = append(errs, fieldErrs...)
+	}
+
+	return errs, nil
+}
+
+func isStruct(t reflect.Type) bool {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	return t.Kind() == reflect.Struct
+}
+
+// fieldName returns the name the field is encoded with, so that it matches
+// the name seen by the client.
+func fieldName(f reflect.StructField) string {
+	if name := strings.Split(f.Tag.Get("json"), ",")[0]; name != "" && name != "-" {
+		return name
+	}
+
+	return f.Name
+}
+
+func joinField(parent, field string) string {
+	if parent == "" {
+		return field
+	}
+
+	return parent + "." + field
+}
+
+func (r Rule) check(v reflect.Value) string {
+	switch r.Name {
+	case "required":
+		if !v.IsValid() || v.IsZero() {
+			return "is required"
+		}
+	case "min", "max", "len":
+		return r.checkSize(v)
+	case "oneof":
+		s := fmt.Sprint(v.Interface())
+		for _, o := range r.options {
+			if s == o {
+				return ""
+			}
+		}
+		return fmt.Sprintf("must be one of [%s]", strings.Join(r.options, " "))
+	case "pattern":
+		if v.Kind() != reflect.String {
+			return fmt.Sprintf("is a %s, which is unable to match a pattern", v.Type())
+		}
+		if !r.expr.MatchString(v.String()) {
+			return fmt.Sprintf("must match %s", r.Arg)
+		}
+	}
+
+	return ""
+}
+
+func (r Rule) checkSize(v reflect.Value) string {
+	var (
+		size float64
+		unit string
+	)
+
+	switch v.Kind() {
+	case reflect.String:
+		size, unit = float64(utf8.RuneCountInString(v.String())), " characters"
+	case reflect.Slice, reflect.Map, reflect.Array:
+		size, unit = float64(v.Len()), " elements"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		size = float64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		size = float64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		size = v.Float()
+	default:
+		return fmt.Sprintf("is a %s, which has no %s", v.Type(), r.Name)
+	}
+
+	switch {
+	case r.Name == "min" && size < r.number:
+		return fmt.Sprintf("must be at least %s%s", r.Arg, unit)
+	case r.Name == "max" && size > r.number:
+		return fmt.Sprintf("must be at most %s%s", r.Arg, unit)
+	case r.Name == "len" && size != r.number:
+		return fmt.Sprintf("must be exactly %s%s", r.Arg, unit)
+	}
+
+	return ""
+}
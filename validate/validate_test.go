@@ -0,0 +1,157 @@
+package validate_test
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/ayiga/go-kit-middlewarer/encoding"
+	"github.com/ayiga/go-kit-middlewarer/validate"
+)
+
+type address struct {
+	City string `json:"city" validate:"required"`
+}
+
+type person struct {
+	Name    string   `json:"name" validate:"required,max=4"`
+	Role    string   `json:"role" validate:"oneof=admin user"`
+	Tags    []string `validate:"max=1"`
+	Address *address `json:"address"`
+	ignored string
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		rules string
+		want  error
+	}{
+		{"required,unique", validate.ErrUnknownRule},
+		{"required=true", validate.ErrInvalidRule},
+		{"max=ten", validate.ErrInvalidRule},
+		{"oneof=", validate.ErrInvalidRule},
+		{"pattern=[", validate.ErrInvalidRule},
+	}
+
+	for _, test := range tests {
+		if _, err := validate.Parse(test.rules); !errors.Is(err, test.want) {
+			t.Errorf("Parse(%q):\ngot:\n\t%v\nwant:\n\t%v", test.rules, err, test.want)
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		rules string
+		value interface{}
+		want  []string
+	}{
+		{"required", "", []string{"is required"}},
+		{"required", (*string)(nil), []string{"is required"}},
+		{"max=3", (*string)(nil), nil},
+		{"required,max=3", "héllo", []string{"must be at most 3 characters"}},
+		{"min=2", []int{1}, []string{"must be at least 2 elements"}},
+		{"len=2", map[string]int{"a": 1, "b": 2}, nil},
+		{"min=1,max=10", 11, []string{"must be at most 10"}},
+		{"min=0.5", 0.25, []string{"must be at least 0.5"}},
+		{"oneof=en fr", "de", []string{"must be one of [en fr]"}},
+		{"oneof=1 2", 2, nil},
+		{"pattern=^[a-z]+$", "abc1", []string{"must match ^[a-z]+$"}},
+		{"pattern=^[a-z]+$", 1, []string{"is a int, which is unable to match a pattern"}},
+	}
+
+	for _, test := range tests {
+		fieldErrs, err := validate.MustParse(test.rules).Check("field", test.value)
+		if err != nil {
+			t.Errorf("Check(%q, %#v): %s", test.rules, test.value, err)
+		}
+
+		var got []string
+		for _, fe := range fieldErrs {
+			if fe.Field != "field" {
+				t.Errorf("Field:\ngot:\n\t%s\nwant:\n\t%s", fe.Field, "field")
+			}
+			got = append(got, fe.Message)
+		}
+
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("Check(%q, %#v):\ngot:\n\t%q\nwant:\n\t%q", test.rules, test.value, got, test.want)
+		}
+	}
+}
+
+func TestStruct(t *testing.T) {
+	p := person{
+		Name:    "Alexander",
+		Role:    "guest",
+		Tags:    []string{"a", "b"},
+		Address: &address{},
+	}
+
+	want := []validate.FieldError{
+		{Field: "p.name", Message: "must be at most 4 characters"},
+		{Field: "p.role", Message: "must be one of [admin user]"},
+		{Field: "p.Tags", Message: "must be at most 1 elements"},
+		{Field: "p.address.city", Message: "is required"},
+	}
+
+	if got, err := validate.Struct("p", &p); err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("Struct:\ngot:\n\t%v %v\nwant:\n\t%v <nil>", got, err, want)
+	}
+
+	p = person{Name: "Al", Role: "admin"}
+	if got, err := validate.Struct("p", p); err != nil || got != nil {
+		t.Errorf("Struct:\ngot:\n\t%v %v\nwant:\n\t%v <nil>", got, err, nil)
+	}
+}
+
+type malformed struct {
+	Name string `validate:"required,maximum=4"`
+}
+
+type containsMalformed struct {
+	Inner malformed
+}
+
+func TestStructInvalidTag(t *testing.T) {
+	// the tag is reported every time, rather than only when first parsed
+	for i := 0; i < 2; i++ {
+		got, err := validate.Struct("c", containsMalformed{})
+		if !errors.Is(err, validate.ErrInvalidTag) {
+			t.Errorf("Error:\ngot:\n\t%v\nwant:\n\t%v", err, validate.ErrInvalidTag)
+		}
+		if got != nil {
+			t.Errorf("Struct:\ngot:\n\t%v\nwant:\n\t%v", got, nil)
+		}
+	}
+}
+
+func TestParseTag(t *testing.T) {
+	rules, ok, err := validate.ParseTag(`json:"name" validate:"required,max=4"`)
+	if err != nil || !ok || len(rules) != 2 {
+		t.Errorf("ParseTag:\ngot:\n\t%v %t %v\nwant:\n\t2 rules true <nil>", rules, ok, err)
+	}
+
+	if _, ok, err := validate.ParseTag(`json:"name"`); err != nil || ok {
+		t.Errorf("ParseTag:\ngot:\n\t%t %v\nwant:\n\tfalse <nil>", ok, err)
+	}
+
+	if _, _, err := validate.ParseTag(`validate:"pattern=["`); !errors.Is(err, validate.ErrInvalidTag) {
+		t.Errorf("Error:\ngot:\n\t%v\nwant:\n\t%v", err, validate.ErrInvalidTag)
+	}
+}
+
+func TestValidationErrorEncodable(t *testing.T) {
+	ve := validate.ValidationError{
+		Fields: []validate.FieldError{{Field: "str", Message: "is required"}},
+	}
+
+	if got, want := encoding.ErrorStatus(ve), http.StatusBadRequest; got != want {
+		t.Errorf("ErrorStatus:\ngot:\n\t%d\nwant:\n\t%d", got, want)
+	}
+
+	if got, want := encoding.WrapError(ve).Err, interface{}(ve); !reflect.DeepEqual(got, want) {
+		t.Errorf("WrapError:\ngot:\n\t%#v\nwant:\n\t%#v", got, want)
+	}
+}
@@ -1,9 +1,10 @@
 package main
 
 type Variable struct {
-	name  string
-	typ   *Type // nillable
-	value string
+	name    string
+	typ     *Type // nillable
+	value   string
+	isError bool // whether the value implements error
 }
 
 func createVariable(name, value string, typ *Type) Variable {